@@ -0,0 +1,223 @@
+package adpcm
+
+import (
+	"math"
+)
+
+var indexTable = [16]int16{
+	-1, -1, -1, -1, 2, 4, 6, 8,
+	-1, -1, -1, -1, 2, 4, 6, 8,
+}
+
+var stepTable = [89]int16{
+	7, 8, 9, 10, 11, 12, 13, 14, 16, 17,
+	19, 21, 23, 25, 28, 31, 34, 37, 41, 45,
+	50, 55, 60, 66, 73, 80, 88, 97, 107, 118,
+	130, 143, 157, 173, 190, 209, 230, 253, 279, 307,
+	337, 371, 408, 449, 494, 544, 598, 658, 724, 796,
+	876, 963, 1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066,
+	2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358,
+	5894, 6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899,
+	15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794, 32767,
+}
+
+// Block is a block of 4-bit IMA ADPCM samples for a single channel. The
+// block starts with its own initial sample and step index, so it can be decoded
+// without decoding the blocks before it.
+type Block struct {
+	sample int16
+	index  int16
+	data   []byte
+}
+
+var _ AnyBlock = (*Block)(nil)
+
+// NewBlock returns a new block with the given initial sample and step
+// index holding the ADPCM data, which has two samples per byte with the first
+// sample in the low 4 bits.
+func NewBlock(sample, index int16, data []byte) *Block {
+	return &Block{
+		sample: sample,
+		index:  index,
+		data:   data,
+	}
+}
+
+// Sample returns the block's initial sample, which is the predictor for its
+// first sample.
+func (b *Block) Sample() int16 { return b.sample }
+
+// Index returns the block's initial index into the step size table.
+func (b *Block) Index() int16 { return b.index }
+
+// Bytes returns the block's ADPCM data.
+func (b *Block) Bytes() ([]byte, error) { return b.data, nil }
+
+// NumSamples returns the number of samples in the block.
+func (b *Block) NumSamples() int { return len(b.data) * 2 }
+
+// AsPCM16Block decodes the block into a new PCM16Block.
+func (b *Block) AsPCM16Block() *PCM16Block {
+	return &PCM16Block{Data: b.AppendPCM16(make([]int16, 0, len(b.data)*2))}
+}
+
+// AppendPCM16 decodes the block and appends its samples to dst, returning the
+// extended slice. Decoding into a reused slice avoids allocating a new block
+// for each decoded block.
+func (b *Block) AppendPCM16(dst []int16) []int16 {
+	d := &blockDecoder{sample: int(b.sample), index: b.index}
+
+	for _, byt := range b.data {
+		dst = append(dst, d.decode(byt&0x0f), d.decode(byt>>4))
+	}
+
+	return dst
+}
+
+// Encoder encodes 16-bit PCM samples as a sequence of IMA ADPCM blocks.
+// The step index is carried from one block to the next, and each block's
+// initial sample is the last input sample of the previous block. This is the
+// encoder's own convention: the game's audio files do not always start a block
+// at the last decoded sample of the previous block. The zero value is ready to
+// use.
+type Encoder struct {
+	sample int16
+	index  int16
+}
+
+// Sample returns the last sample that was encoded, or 0 if no samples have been
+// encoded.
+func (e *Encoder) Sample() int16 { return e.sample }
+
+// Index returns the step index that the next block will start with.
+func (e *Encoder) Index() int16 { return e.index }
+
+// EncodeBlock encodes data as a new block holding len(data)/2 bytes of ADPCM
+// data. If data has an odd number of samples, the last sample is repeated.
+func (e *Encoder) EncodeBlock(data []int16) *Block {
+	b := &Block{
+		sample: e.sample,
+		index:  e.index,
+		data:   make([]byte, (len(data)+1)/2),
+	}
+	if len(data) == 0 {
+		return b
+	}
+
+	d := &blockDecoder{sample: int(e.sample), index: e.index}
+
+	for i := range b.data {
+		lo := data[2*i]
+		hi := lo
+		if 2*i+1 < len(data) {
+			hi = data[2*i+1]
+		}
+		b.data[i] = d.encode(lo) | d.encode(hi)<<4
+	}
+
+	e.sample = data[len(data)-1]
+	e.index = d.index
+
+	return b
+}
+
+type blockDecoder struct {
+	sample int
+	index  int16
+}
+
+// originalSample is a 4-bit ADPCM sample.
+//
+// The return value newSample is the resulting 16-bit two's complement variable.
+//
+// See https://www.cs.columbia.edu/~hgs/audio/dvi/IMA_ADPCM.pdf at page 32 for
+// the algorithm and for example input. Note: The example input does appear to
+// be wrong though because `if (0x8763 > 32767) == FALSE` is actually true.
+func (d *blockDecoder) decode(originalSample byte) int16 {
+	// Find quantizer step size.
+	stepSize := int(stepTable[d.index])
+
+	// Calculate difference:
+	//
+	//   diff = (originalSample + 1/2) * stepSize/4
+	//
+	// Perform multiplication through repetitive addition.
+	var diff int
+	if originalSample&4 != 0 {
+		diff += stepSize
+	}
+	if originalSample&2 != 0 {
+		diff += stepSize >> 1
+	}
+	if originalSample&1 != 0 {
+		diff += stepSize >> 2
+	}
+	diff += stepSize >> 3
+
+	// Account for sign bit.
+	if originalSample&8 != 0 {
+		diff = -diff
+	}
+
+	// Adjust predicted sample based on calculated difference.
+	newSample := d.sample + diff
+	if newSample > math.MaxInt16 { // check for overflow
+		newSample = math.MaxInt16
+	} else if newSample < math.MinInt16 {
+		newSample = math.MinInt16
+	}
+
+	// Store 16-bit new sample.
+	d.sample = newSample
+
+	// Adjust index into step size lookup table using original sample.
+	index := d.index + indexTable[originalSample]
+	if index < 0 { // check for index underflow
+		index = 0
+	} else if index > 88 { // check for index overflow
+		index = 88
+	}
+	d.index = index
+
+	// Value has been clamped, can now convert to int16.
+	return int16(newSample)
+}
+
+// encode returns the 4-bit ADPCM sample that best approximates sample and
+// updates the decoder's state as though that ADPCM sample had been decoded, so
+// that the decoder tracks what a decoder reading the block would produce.
+//
+// See https://www.cs.columbia.edu/~hgs/audio/dvi/IMA_ADPCM.pdf at page 31 for
+// the algorithm.
+func (d *blockDecoder) encode(sample int16) byte {
+	stepSize := int(stepTable[d.index])
+
+	// Calculate difference from the predicted sample.
+	diff := int(sample) - d.sample
+
+	// Set sign bit.
+	var originalSample byte
+	if diff < 0 {
+		originalSample = 8
+		diff = -diff
+	}
+
+	// Quantize the difference into the remaining 3 bits.
+	if diff >= stepSize {
+		originalSample |= 4
+		diff -= stepSize
+	}
+	stepSize >>= 1
+	if diff >= stepSize {
+		originalSample |= 2
+		diff -= stepSize
+	}
+	stepSize >>= 1
+	if diff >= stepSize {
+		originalSample |= 1
+	}
+
+	d.decode(originalSample)
+
+	return originalSample
+}
@@ -0,0 +1,85 @@
+package adpcm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+)
+
+// See https://www.cs.columbia.edu/~hgs/audio/dvi/IMA_ADPCM.pdf at page 32 for
+// the algorithm and for example input. Note: The example input does appear to
+// be wrong though because `if (0x8763 > 32767) == FALSE` is actually true.
+func Test_blockDecoder_decode(t *testing.T) {
+	d := &blockDecoder{
+		sample: 0x8700,
+		index:  24,
+	}
+	var originalSample byte = 0x3
+
+	t.Run("returns new sample", func(t *testing.T) {
+		got := d.decode(originalSample)
+		var want int16 = 0x7FFF
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("save new sample on decoder", func(t *testing.T) {
+		got := d.sample
+		var want int = 0x7FFF
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("save new index on decoder", func(t *testing.T) {
+		got := d.index
+		var want int16 = 23
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEncoder_EncodeBlock(t *testing.T) {
+	// A 440 Hz tone at 22050 Hz, the sample rate of the game's audio files.
+	data := audiotest.Tone(22050, 2040*3, 440, 8000)
+
+	e := &Encoder{}
+	var got []int16
+	for i := 0; i < len(data); i += 2040 {
+		b := e.EncodeBlock(data[i : i+2040])
+		if n := len(b.data); n != 1020 {
+			t.Fatalf("block %d has %d byte(s), want %d", i/2040, n, 1020)
+		}
+		if i > 0 && b.Sample() != data[i-1] {
+			t.Errorf("block %d sample = %d, want last sample of previous block %d", i/2040, b.Sample(), data[i-1])
+		}
+		got = append(got, b.AsPCM16Block().Data...)
+	}
+
+	// Once the step index has adapted to the signal, each decoded sample should
+	// be within half of a step of the original. The largest step for this
+	// signal is well under 1024.
+	for i := audiotest.Warmup; i < len(data); i++ {
+		if diff := math.Abs(float64(got[i]) - float64(data[i])); diff > 512 {
+			t.Fatalf("sample %d = %d, want within %d of %d", i, got[i], 512, data[i])
+		}
+	}
+
+	if snr := audiotest.SignalToNoise(data[audiotest.Warmup:], got[audiotest.Warmup:]); snr < 30 {
+		t.Errorf("signal-to-noise ratio = %.1f dB, want at least 30 dB", snr)
+	}
+}
+
+func TestEncoder_EncodeBlock_oddLength(t *testing.T) {
+	e := &Encoder{}
+	b := e.EncodeBlock([]int16{100, 200, 300})
+	if n := len(b.data); n != 2 {
+		t.Fatalf("block has %d byte(s), want %d", n, 2)
+	}
+	if got, want := e.Sample(), int16(300); got != want {
+		t.Errorf("Sample() = %d, want %d", got, want)
+	}
+}
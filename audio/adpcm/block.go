@@ -0,0 +1,28 @@
+// Package adpcm implements the blocks of IMA ADPCM and 16-bit PCM samples that
+// make up Dark Omen's .MAD and .SAD audio files.
+package adpcm
+
+// AnyBlock is a block of samples for a single channel, either a *Block of
+// ADPCM samples or a *PCM16Block.
+type AnyBlock interface {
+	// Bytes returns the block's encoded data.
+	Bytes() ([]byte, error)
+	// AsPCM16Block returns the block decoded to 16-bit PCM samples.
+	AsPCM16Block() *PCM16Block
+	// NumSamples returns the number of samples in the block without decoding
+	// it.
+	NumSamples() int
+}
+
+// AppendSamples appends the decoded samples of each block to dst and returns
+// the extended slice.
+func AppendSamples(dst []int16, blocks []AnyBlock) []int16 {
+	for _, b := range blocks {
+		if a, ok := b.(*Block); ok {
+			dst = a.AppendPCM16(dst)
+		} else {
+			dst = append(dst, b.AsPCM16Block().Data...)
+		}
+	}
+	return dst
+}
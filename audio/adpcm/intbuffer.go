@@ -0,0 +1,91 @@
+package adpcm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-audio/audio"
+)
+
+// ToIntBuffer decodes each channel's blocks and returns them as a single
+// go-audio buffer of interleaved 16-bit samples at sampleRate Hz. Pass one
+// slice of blocks for mono audio and two for stereo. Every channel must have
+// the same number of samples.
+func ToIntBuffer(sampleRate int, channels ...[]AnyBlock) (*audio.IntBuffer, error) {
+	var n int
+	for c, blocks := range channels {
+		var m int
+		for _, b := range blocks {
+			m += b.NumSamples()
+		}
+		if c == 0 {
+			n = m
+		} else if m != n {
+			return nil, fmt.Errorf("channel %d has %d sample(s), channel 0 has %d", c, m, n)
+		}
+	}
+
+	buf := &audio.IntBuffer{
+		Format: &audio.Format{
+			NumChannels: len(channels),
+			SampleRate:  sampleRate,
+		},
+		Data:           make([]int, n*len(channels)),
+		SourceBitDepth: 16,
+	}
+
+	var samples []int16
+	for c, blocks := range channels {
+		i := c
+		for _, b := range blocks {
+			if a, ok := b.(*Block); ok {
+				samples = a.AppendPCM16(samples[:0])
+			} else {
+				samples = b.AsPCM16Block().Data
+			}
+			for _, v := range samples {
+				buf.Data[i] = int(v)
+				i += len(channels)
+			}
+		}
+	}
+
+	return buf, nil
+}
+
+// FromIntBuffer returns one PCM16Block per channel holding the samples of buf.
+// Samples are scaled from buf.SourceBitDepth to 16 bits, or assumed to be 16
+// bits if the source bit depth is not set. Samples of 8 bits are assumed to be
+// unsigned, as in WAV files.
+func FromIntBuffer(buf *audio.IntBuffer) ([]*PCM16Block, error) {
+	if buf.Format == nil || buf.Format.NumChannels < 1 {
+		return nil, errors.New("buffer has no channels")
+	}
+	channels := buf.Format.NumChannels
+	depth := buf.SourceBitDepth
+	if depth == 0 {
+		depth = 16
+	}
+	if depth < 8 || depth > 32 {
+		return nil, fmt.Errorf("unsupported bit depth %d", depth)
+	}
+
+	blocks := make([]*PCM16Block, channels)
+	for c := range blocks {
+		blocks[c] = &PCM16Block{Data: make([]int16, len(buf.Data)/channels)}
+	}
+
+	for i, v := range buf.Data[:len(buf.Data)/channels*channels] {
+		if depth == 8 {
+			v -= 0x80
+		}
+		if depth > 16 {
+			v >>= depth - 16
+		} else {
+			v <<= 16 - depth
+		}
+		blocks[i%channels].Data[i/channels] = int16(v)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,105 @@
+package adpcm
+
+import (
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestToIntBuffer(t *testing.T) {
+	e := &Encoder{}
+	left := []AnyBlock{
+		e.EncodeBlock([]int16{100, 200, 300, 400}),
+		NewPCM16BlockFromInt16Slice([]int16{500}),
+	}
+	right := []AnyBlock{
+		NewPCM16BlockFromInt16Slice([]int16{-1, -2, -3, -4, -5}),
+	}
+
+	got, err := ToIntBuffer(22050, left, right)
+	if err != nil {
+		t.Fatalf("ToIntBuffer() error = %v, want nil", err)
+	}
+
+	decoded := left[0].AsPCM16Block().Data
+	want := &audio.IntBuffer{
+		Format: &audio.Format{NumChannels: 2, SampleRate: 22050},
+		Data: []int{
+			int(decoded[0]), -1,
+			int(decoded[1]), -2,
+			int(decoded[2]), -3,
+			int(decoded[3]), -4,
+			500, -5,
+		},
+		SourceBitDepth: 16,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ToIntBuffer() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ToIntBuffer(22050, left, right[:0]); err == nil {
+		t.Error("ToIntBuffer() with mismatched channels error = nil, want error")
+	}
+}
+
+func TestFromIntBuffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		buf     *audio.IntBuffer
+		want    []*PCM16Block
+		wantErr bool
+	}{
+		{
+			name: "16-bit stereo",
+			buf: &audio.IntBuffer{
+				Format:         &audio.Format{NumChannels: 2, SampleRate: 22050},
+				Data:           []int{1, -1, 2, -2},
+				SourceBitDepth: 16,
+			},
+			want: []*PCM16Block{{Data: []int16{1, 2}}, {Data: []int16{-1, -2}}},
+		},
+		{
+			name: "unset bit depth",
+			buf: &audio.IntBuffer{
+				Format: &audio.Format{NumChannels: 1, SampleRate: 22050},
+				Data:   []int{1, 2},
+			},
+			want: []*PCM16Block{{Data: []int16{1, 2}}},
+		},
+		{
+			name: "8-bit",
+			buf: &audio.IntBuffer{
+				Format:         &audio.Format{NumChannels: 1, SampleRate: 22050},
+				Data:           []int{0x80, 0x81, 0x00},
+				SourceBitDepth: 8,
+			},
+			want: []*PCM16Block{{Data: []int16{0, 0x100, -0x8000}}},
+		},
+		{
+			name: "24-bit",
+			buf: &audio.IntBuffer{
+				Format:         &audio.Format{NumChannels: 1, SampleRate: 22050},
+				Data:           []int{0x7FFFFF, -0x800000, 0x100},
+				SourceBitDepth: 24,
+			},
+			want: []*PCM16Block{{Data: []int16{0x7FFF, -0x8000, 1}}},
+		},
+		{
+			name:    "no format",
+			buf:     &audio.IntBuffer{Data: []int{1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromIntBuffer(tt.buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromIntBuffer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FromIntBuffer() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
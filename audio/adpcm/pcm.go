@@ -0,0 +1,51 @@
+package adpcm
+
+import (
+	"encoding/binary"
+)
+
+// PCM16Block is a block of 16-bit PCM samples for a single channel.
+type PCM16Block struct {
+	Data []int16
+}
+
+var _ AnyBlock = (*PCM16Block)(nil)
+
+// NewPCM16Block returns a new block holding the 16-bit little-endian samples
+// in bs. A trailing odd byte is ignored. The only allocations are the block
+// and its samples.
+func NewPCM16Block(bs []byte) *PCM16Block {
+	data := make([]int16, len(bs)/2)
+	for i := range data {
+		data[i] = int16(binary.LittleEndian.Uint16(bs[i*2:]))
+	}
+	return &PCM16Block{Data: data}
+}
+
+// NewPCM16BlockFromInt16Slice returns a new block holding data. The block
+// refers to data rather than copying it.
+func NewPCM16BlockFromInt16Slice(data []int16) *PCM16Block {
+	return &PCM16Block{Data: data}
+}
+
+// Bytes returns the block's samples as 16-bit little-endian PCM. The only
+// allocation is the returned slice; AppendBytes can encode into a reused
+// buffer without allocating.
+func (b *PCM16Block) Bytes() ([]byte, error) {
+	return b.AppendBytes(make([]byte, 0, len(b.Data)*2)), nil
+}
+
+// AppendBytes appends the block's samples as 16-bit little-endian PCM to dst
+// and returns the extended slice.
+func (b *PCM16Block) AppendBytes(dst []byte) []byte {
+	for _, v := range b.Data {
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(v))
+	}
+	return dst
+}
+
+// AsPCM16Block returns b.
+func (b *PCM16Block) AsPCM16Block() *PCM16Block { return b }
+
+// NumSamples returns the number of samples in the block.
+func (b *PCM16Block) NumSamples() int { return len(b.Data) }
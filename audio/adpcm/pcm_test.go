@@ -0,0 +1,72 @@
+package adpcm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPCM16Block_Bytes(t *testing.T) {
+	bs := []byte{0x00, 0x00, 0x01, 0x00, 0xFF, 0xFF, 0xFF, 0x7F, 0x00, 0x80}
+	want := []int16{0, 1, -1, math.MaxInt16, math.MinInt16}
+
+	b := NewPCM16Block(bs)
+	if diff := cmp.Diff(want, b.Data); diff != "" {
+		t.Errorf("NewPCM16Block() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(bs, got); diff != "" {
+		t.Errorf("Bytes() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPCM16Block_allocs(t *testing.T) {
+	bs := make([]byte, 4096)
+	b := NewPCM16BlockFromInt16Slice(make([]int16, 2048))
+	dst := make([]byte, 0, 4096)
+
+	tests := []struct {
+		name string
+		f    func()
+		want float64
+	}{
+		{
+			name: "NewPCM16Block",
+			f:    func() { _ = NewPCM16Block(bs) },
+			// The block and its samples.
+			want: 2,
+		},
+		{
+			name: "Bytes",
+			f:    func() { _, _ = b.Bytes() },
+			want: 1,
+		},
+		{
+			name: "AppendBytes",
+			f:    func() { dst = b.AppendBytes(dst[:0]) },
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(10, tt.f); got > tt.want {
+				t.Errorf("allocations = %v, want at most %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkPCM16Block_Bytes(b *testing.B) {
+	block := NewPCM16BlockFromInt16Slice(make([]int16, 2040))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := block.Bytes(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package loudness measures the level of 16-bit PCM audio and normalizes it to
+// a target loudness.
+//
+// Integrated loudness is measured as specified by ITU-R BS.1770-4 and EBU
+// R 128: the audio is K-weighted, split into overlapping 400 ms blocks, and
+// gated to ignore silence and quiet passages.
+package loudness
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+)
+
+const (
+	// fullScale is the amplitude of a full-scale 16-bit sample.
+	fullScale = math.MaxInt16 + 1
+
+	// absoluteGate is the loudness, in LUFS, below which blocks are ignored.
+	absoluteGate = -70
+	// relativeGate is the loudness, in LU relative to the absolute-gated
+	// loudness, below which blocks are ignored.
+	relativeGate = -10
+)
+
+// ErrNoLoudness is returned when audio is too short or too quiet for its
+// integrated loudness to be measured.
+var ErrNoLoudness = errors.New("audio is too short or too quiet to measure its loudness")
+
+// Measurement is the level of some audio. Silent audio has a level of negative
+// infinity.
+type Measurement struct {
+	// Peak is the largest absolute sample of any channel, in dBFS.
+	Peak float64
+	// RMS is the root mean square of the samples of every channel, in dBFS.
+	RMS float64
+	// Integrated is the gated integrated loudness, in LUFS. It is negative
+	// infinity for audio shorter than 400 ms or without a block louder than
+	// -70 LUFS.
+	Integrated float64
+}
+
+// Measure measures the 16-bit PCM samples at sampleRate Hz. Pass one slice of
+// samples for mono audio and two for stereo. Every channel must have the same
+// number of samples.
+func Measure(sampleRate int, channels ...[]int16) (Measurement, error) {
+	if err := validate(sampleRate, channels); err != nil {
+		return Measurement{}, err
+	}
+
+	var (
+		peak   int
+		sumSq  float64
+		sample int
+	)
+	for _, ch := range channels {
+		for _, v := range ch {
+			v := int(v)
+			peak = max(peak, v, -v)
+			sumSq += float64(v) * float64(v)
+			sample++
+		}
+	}
+
+	m := Measurement{
+		Peak:       math.Inf(-1),
+		RMS:        math.Inf(-1),
+		Integrated: integrated(sampleRate, channels),
+	}
+	if peak > 0 {
+		m.Peak = 20 * math.Log10(float64(peak)/fullScale)
+		m.RMS = 10 * math.Log10(sumSq/float64(sample)/(fullScale*fullScale))
+	}
+	return m, nil
+}
+
+// MeasureBlocks decodes each channel's blocks and measures them as Measure
+// does.
+func MeasureBlocks(sampleRate int, channels ...[]adpcm.AnyBlock) (Measurement, error) {
+	samples := make([][]int16, len(channels))
+	for c, blocks := range channels {
+		samples[c] = adpcm.AppendSamples(nil, blocks)
+	}
+	return Measure(sampleRate, samples...)
+}
+
+// Normalize returns a copy of the 16-bit PCM samples at sampleRate Hz with a
+// gain applied so that their integrated loudness is target LUFS. Samples that
+// would exceed 16 bits are clipped, so loud targets may not be reached. It
+// returns ErrNoLoudness if the loudness of the samples cannot be measured.
+func Normalize(sampleRate int, target float64, channels ...[]int16) ([][]int16, error) {
+	m, err := Measure(sampleRate, channels...)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsInf(m.Integrated, -1) {
+		return nil, ErrNoLoudness
+	}
+	return Gain(target-m.Integrated, channels...), nil
+}
+
+// Gain returns a copy of the 16-bit PCM samples amplified by db decibels.
+// Samples are rounded to the nearest integer and clipped to 16 bits.
+func Gain(db float64, channels ...[]int16) [][]int16 {
+	scale := math.Pow(10, db/20)
+	out := make([][]int16, len(channels))
+	for c, ch := range channels {
+		out[c] = make([]int16, len(ch))
+		for i, v := range ch {
+			out[c][i] = clamp(math.Round(float64(v) * scale))
+		}
+	}
+	return out
+}
+
+func clamp(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+func validate(sampleRate int, channels [][]int16) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	if len(channels) == 0 {
+		return errors.New("no channels")
+	}
+	for c, ch := range channels {
+		if len(ch) != len(channels[0]) {
+			return fmt.Errorf("channel %d has %d sample(s), channel 0 has %d", c, len(ch), len(channels[0]))
+		}
+	}
+	return nil
+}
+
+// integrated returns the gated integrated loudness of the channels, in LUFS.
+// Every channel has a weighting of 1, which is correct for mono and for the
+// left and right channels of stereo audio.
+func integrated(sampleRate int, channels [][]int16) float64 {
+	// Gating blocks are 400 ms long and overlap by 75%, so each block is four
+	// 100 ms steps long.
+	step := int(math.Round(0.1 * float64(sampleRate)))
+	n := len(channels[0])
+	if n < 4*step {
+		return math.Inf(-1)
+	}
+	numBlocks := n/step - 3
+
+	// The sum of squares of each block is the sum of those of its steps, so
+	// each step of each channel is only summed once.
+	steps := make([]float64, numBlocks+3)
+	for _, ch := range channels {
+		f := newKWeighting(float64(sampleRate))
+		for i, v := range ch[:len(steps)*step] {
+			y := f.filter(float64(v) / fullScale)
+			steps[i/step] += y * y
+		}
+	}
+
+	powers := make([]float64, numBlocks)
+	for j := range powers {
+		var sum float64
+		for _, s := range steps[j : j+4] {
+			sum += s
+		}
+		powers[j] = sum / float64(4*step)
+	}
+
+	gated := func(threshold float64) float64 {
+		var sum float64
+		var count int
+		for _, p := range powers {
+			if blockLoudness(p) > threshold {
+				sum += p
+				count++
+			}
+		}
+		if count == 0 {
+			return math.Inf(-1)
+		}
+		return blockLoudness(sum / float64(count))
+	}
+
+	abs := gated(absoluteGate)
+	if math.IsInf(abs, -1) {
+		return abs
+	}
+	return gated(abs + relativeGate)
+}
+
+// blockLoudness returns the loudness, in LUFS, of a block whose channels have
+// the given sum of K-weighted mean squares.
+func blockLoudness(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
+
+// kWeighting is the K-weighting filter of BS.1770: a high shelf that models
+// the acoustic effect of the head, followed by a high-pass filter.
+type kWeighting struct {
+	shelf, highPass biquad
+}
+
+// newKWeighting returns the K-weighting filter for the sample rate. The
+// coefficients are derived from the analog prototypes of the filters, so they
+// match those given in BS.1770 at 48 kHz.
+func newKWeighting(sampleRate float64) *kWeighting {
+	var k kWeighting
+
+	const (
+		shelfFreq = 1681.974450955533
+		shelfGain = 3.999843853973347
+		shelfQ    = 0.7071752369554196
+	)
+	K := math.Tan(math.Pi * shelfFreq / sampleRate)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + K/shelfQ + K*K
+	k.shelf = biquad{
+		b0: (vh + vb*K/shelfQ + K*K) / a0,
+		b1: 2 * (K*K - vh) / a0,
+		b2: (vh - vb*K/shelfQ + K*K) / a0,
+		a1: 2 * (K*K - 1) / a0,
+		a2: (1 - K/shelfQ + K*K) / a0,
+	}
+
+	const (
+		highPassFreq = 38.13547087602444
+		highPassQ    = 0.5003270373238773
+	)
+	K = math.Tan(math.Pi * highPassFreq / sampleRate)
+	a0 = 1 + K/highPassQ + K*K
+	k.highPass = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (K*K - 1) / a0,
+		a2: (1 - K/highPassQ + K*K) / a0,
+	}
+
+	return &k
+}
+
+func (k *kWeighting) filter(x float64) float64 {
+	return k.highPass.filter(k.shelf.filter(x))
+}
+
+// biquad is a second-order IIR filter in direct form I.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) filter(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
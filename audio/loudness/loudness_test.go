@@ -0,0 +1,203 @@
+package loudness
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+)
+
+// sine returns seconds of a sine wave with a peak of dbfs dBFS.
+func sine(sampleRate int, freq, dbfs, seconds float64) []int16 {
+	return audiotest.Tone(sampleRate, int(seconds*float64(sampleRate)), freq, fullScale*math.Pow(10, dbfs/20))
+}
+
+func TestNewKWeighting(t *testing.T) {
+	// The coefficients given in BS.1770-4 for 48 kHz.
+	want := &kWeighting{
+		shelf: biquad{
+			b0: 1.53512485958697,
+			b1: -2.69169618940638,
+			b2: 1.19839281085285,
+			a1: -1.69065929318241,
+			a2: 0.73248077421585,
+		},
+		highPass: biquad{
+			b0: 1,
+			b1: -2,
+			b2: 1,
+			a1: -1.99004745483398,
+			a2: 0.99007225036621,
+		},
+	}
+	got := newKWeighting(48000)
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(kWeighting{}, biquad{}), cmpopts.EquateApprox(0, 1e-8)); diff != "" {
+		t.Errorf("newKWeighting() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	silence := make([]int16, 22050)
+	tone := sine(22050, 1000, -23, 5)
+
+	tests := []struct {
+		name       string
+		sampleRate int
+		channels   [][]int16
+		want       Measurement
+	}{
+		{
+			// EBU Tech 3341 test case 1.
+			name:       "stereo 1 kHz at -23 dBFS",
+			sampleRate: 48000,
+			channels:   [][]int16{sine(48000, 1000, -23, 20), sine(48000, 1000, -23, 20)},
+			want:       Measurement{Peak: -23, RMS: -26.01, Integrated: -23},
+		},
+		{
+			name:       "stereo 1 kHz at -23 dBFS at 22050 Hz",
+			sampleRate: 22050,
+			channels:   [][]int16{tone, tone},
+			want:       Measurement{Peak: -23, RMS: -26.01, Integrated: -23},
+		},
+		{
+			name:       "mono 1 kHz at -23 dBFS",
+			sampleRate: 22050,
+			channels:   [][]int16{tone},
+			want:       Measurement{Peak: -23, RMS: -26.01, Integrated: -26.01},
+		},
+		{
+			// Blocks of only silence are gated, so only the blocks at either end
+			// of the tone, which are partly silent, lower the loudness.
+			name:       "tone and silence",
+			sampleRate: 22050,
+			channels:   [][]int16{append(append(append([]int16(nil), silence...), tone...), silence...)},
+			want:       Measurement{Peak: -23, RMS: -27.46, Integrated: -26.23},
+		},
+		{
+			name:       "silence",
+			sampleRate: 22050,
+			channels:   [][]int16{silence, silence},
+			want:       Measurement{Peak: math.Inf(-1), RMS: math.Inf(-1), Integrated: math.Inf(-1)},
+		},
+		{
+			name:       "shorter than a block",
+			sampleRate: 22050,
+			channels:   [][]int16{tone[:8000]},
+			want:       Measurement{Peak: -23, RMS: -26.01, Integrated: math.Inf(-1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Measure(tt.sampleRate, tt.channels...)
+			if err != nil {
+				t.Fatalf("Measure() error = %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateApprox(0, 0.05)); diff != "" {
+				t.Errorf("Measure() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMeasure_invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate int
+		channels   [][]int16
+	}{
+		{name: "no channels", sampleRate: 22050},
+		{name: "invalid sample rate", sampleRate: 0, channels: [][]int16{{0}}},
+		{name: "mismatched channels", sampleRate: 22050, channels: [][]int16{{0, 1}, {0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Measure(tt.sampleRate, tt.channels...); err == nil {
+				t.Error("Measure() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestMeasureBlocks(t *testing.T) {
+	tone := sine(22050, 440, -12, 2)
+	enc := &adpcm.Encoder{}
+	blocks := []adpcm.AnyBlock{
+		enc.EncodeBlock(tone[:2040]),
+		enc.EncodeBlock(tone[2040:4080]),
+		adpcm.NewPCM16BlockFromInt16Slice(tone[4080:]),
+	}
+
+	got, err := MeasureBlocks(22050, blocks)
+	if err != nil {
+		t.Fatalf("MeasureBlocks() error = %v, want nil", err)
+	}
+	want, err := Measure(22050, adpcm.AppendSamples(nil, blocks))
+	if err != nil {
+		t.Fatalf("Measure() error = %v, want nil", err)
+	}
+	if got != want {
+		t.Errorf("MeasureBlocks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   float64
+		channels [][]int16
+	}{
+		{
+			name:     "quieter",
+			target:   -30,
+			channels: [][]int16{sine(22050, 440, -10, 3)},
+		},
+		{
+			name:     "louder",
+			target:   -16,
+			channels: [][]int16{sine(22050, 440, -30, 3), sine(22050, 660, -36, 3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(22050, tt.target, tt.channels...)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v, want nil", err)
+			}
+			m, err := Measure(22050, got...)
+			if err != nil {
+				t.Fatalf("Measure() error = %v, want nil", err)
+			}
+			if d := math.Abs(m.Integrated - tt.target); d > 0.05 {
+				t.Errorf("Normalize() loudness = %.2f LUFS, want %.2f LUFS", m.Integrated, tt.target)
+			}
+		})
+	}
+}
+
+func TestNormalize_silence(t *testing.T) {
+	_, err := Normalize(22050, -23, make([]int16, 22050))
+	if !errors.Is(err, ErrNoLoudness) {
+		t.Errorf("Normalize() error = %v, want %v", err, ErrNoLoudness)
+	}
+}
+
+func TestGain(t *testing.T) {
+	got := Gain(20*math.Log10(2), []int16{0, 1, -1000, 20000, -20000})
+	want := [][]int16{{0, 2, -2000, math.MaxInt16, math.MinInt16}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Gain() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func BenchmarkMeasure(b *testing.B) {
+	samples := sine(22050, 440, -12, 60)
+	for i := 0; i < b.N; i++ {
+		if _, err := Measure(22050, samples, samples); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
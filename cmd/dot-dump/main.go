@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jonathaningram/dark-omen/encoding/dot"
+	"golang.org/x/image/bmp"
+)
+
+// overlayMargin is the number of pixels around the paths in an overlay when the
+// map bitmap could not be found.
+const overlayMargin = 10
+
+// findBitmap returns the map bitmap named fileName from dir, matching the name
+// case-insensitively. It returns nil if there is no such bitmap.
+func findBitmap(dir, fileName string) (image.Image, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !strings.EqualFold(e.Name(), fileName) {
+			continue
+		}
+		f, err := os.Open(path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		img, err := bmp.Decode(f)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s: %w", e.Name(), err)
+		}
+		return img, nil
+	}
+	return nil, nil
+}
+
+// pathBounds returns the bounds of an image large enough to contain every path
+// in m.
+func pathBounds(m *dot.Map) image.Rectangle {
+	var r image.Rectangle
+	for _, p := range m.Paths {
+		for _, pt := range p.Points {
+			r.Max.X = max(r.Max.X, int(pt.X)+overlayMargin)
+			r.Max.Y = max(r.Max.Y, int(pt.Y)+overlayMargin)
+		}
+	}
+	return r
+}
+
+func writePNG(file string, img image.Image) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := png.Encode(out, img); err != nil {
+		return fmt.Errorf("could not encode PNG file: %w", err)
+	}
+
+	return out.Sync()
+}
+
+func writeMap(m *dot.Map, srcDir, dir string) error {
+	background, err := findBitmap(srcDir, m.FileName)
+	if err != nil {
+		return fmt.Errorf("could not read map bitmap: %w", err)
+	}
+
+	bounds := pathBounds(m)
+	if background != nil {
+		bounds = background.Bounds()
+	}
+
+	overlay := image.NewNRGBA(bounds)
+	dot.Render(overlay, m, nil)
+	if err := writePNG(path.Join(dir, "overlay.png"), overlay); err != nil {
+		return fmt.Errorf("could not write overlay: %w", err)
+	}
+
+	if background != nil {
+		composite := image.NewNRGBA(bounds)
+		draw.Draw(composite, bounds, background, bounds.Min, draw.Src)
+		draw.Draw(composite, bounds, overlay, bounds.Min, draw.Over)
+		if err := writePNG(path.Join(dir, "map.png"), composite); err != nil {
+			return fmt.Errorf("could not write map: %w", err)
+		}
+	}
+
+	if err := writeFile(path.Join(dir, "paths.svg"), func(w io.Writer) error {
+		return dot.WriteSVG(w, m, bounds.Size(), nil)
+	}); err != nil {
+		return fmt.Errorf("could not write SVG file: %w", err)
+	}
+
+	if err := writeFile(path.Join(dir, "paths.geojson"), func(w io.Writer) error {
+		return dot.WriteGeoJSON(w, m)
+	}); err != nil {
+		return fmt.Errorf("could not write GeoJSON file: %w", err)
+	}
+
+	return nil
+}
+
+func writeFile(file string, write func(w io.Writer) error) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := write(out); err != nil {
+		return err
+	}
+
+	return out.Sync()
+}
+
+func main() {
+	const (
+		flagDarkOmenPath = "dark-omen-path"
+		flagOutputPath   = "output-path"
+	)
+
+	var (
+		darkOmenPath = flag.String(flagDarkOmenPath, "", "path to Dark Omen CD data")
+		outputPath   = flag.String(flagOutputPath, "", "path to directory in which paths will be dumped")
+	)
+
+	flag.Parse()
+
+	if *darkOmenPath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *outputPath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	err := filepath.Walk(*darkOmenPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.ToUpper(path.Ext(info.Name())) != ".DOT" {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		relativePath := strings.TrimPrefix(p, *darkOmenPath)
+
+		fmt.Printf("Decoding %s...", relativePath)
+
+		d := dot.NewDecoder(f)
+
+		m, err := d.Decode()
+		if err != nil {
+			fmt.Printf("failed\n")
+			return fmt.Errorf("could not decode %s: %w", relativePath, err)
+		}
+
+		fmt.Printf("ok\n")
+
+		dir := path.Join(*outputPath, relativePath)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+
+		fmt.Printf("Creating %d path(s) for %s...", len(m.Paths), relativePath)
+
+		if err := writeMap(m, filepath.Dir(p), dir); err != nil {
+			fmt.Printf("failed\n")
+			return fmt.Errorf("could not write paths for %s: %w", relativePath, err)
+		}
+
+		fmt.Printf("ok\n")
+
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
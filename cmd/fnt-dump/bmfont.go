@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"unicode/utf8"
+
+	"github.com/jonathaningram/dark-omen/encoding/fnt"
+)
+
+const (
+	// atlasMinWidth is the minimum width of a glyph atlas. Atlases are only
+	// wider when a font has a glyph wider than this.
+	atlasMinWidth = 256
+	// atlasPadding is the number of pixels between glyphs in an atlas.
+	atlasPadding = 1
+)
+
+// bmChar is a glyph in an AngelCode BMFont descriptor. See
+// https://www.angelcode.com/products/bmfont/doc/file_format.html.
+type bmChar struct {
+	ID       int `xml:"id,attr"`
+	X        int `xml:"x,attr"`
+	Y        int `xml:"y,attr"`
+	Width    int `xml:"width,attr"`
+	Height   int `xml:"height,attr"`
+	XOffset  int `xml:"xoffset,attr"`
+	YOffset  int `xml:"yoffset,attr"`
+	XAdvance int `xml:"xadvance,attr"`
+	Page     int `xml:"page,attr"`
+	Channel  int `xml:"chnl,attr"`
+}
+
+// bmPage is an atlas page in an AngelCode BMFont descriptor.
+type bmPage struct {
+	ID   int    `xml:"id,attr"`
+	File string `xml:"file,attr"`
+}
+
+// bmFont is an AngelCode BMFont descriptor for a font with a single atlas
+// page.
+type bmFont struct {
+	XMLName xml.Name `xml:"font"`
+	Info    struct {
+		Face    string `xml:"face,attr"`
+		Size    int    `xml:"size,attr"`
+		Unicode int    `xml:"unicode,attr"`
+		Spacing string `xml:"spacing,attr"`
+	} `xml:"info"`
+	Common struct {
+		LineHeight int `xml:"lineHeight,attr"`
+		Base       int `xml:"base,attr"`
+		ScaleW     int `xml:"scaleW,attr"`
+		ScaleH     int `xml:"scaleH,attr"`
+		Pages      int `xml:"pages,attr"`
+		Packed     int `xml:"packed,attr"`
+	} `xml:"common"`
+	Pages []bmPage `xml:"pages>page"`
+	Chars struct {
+		Count int      `xml:"count,attr"`
+		Chars []bmChar `xml:"char"`
+	} `xml:"chars"`
+}
+
+// buildAtlas packs the glyphs of f into rows of a single atlas image and
+// returns it along with a BMFont descriptor that references the atlas as
+// atlasFile.
+func buildAtlas(f *fnt.Font, name, atlasFile string) (*image.NRGBA, *bmFont) {
+	width := atlasMinWidth
+	for _, g := range f.Glyphs {
+		if g.Image != nil {
+			width = max(width, g.Image.Rect.Dx()+atlasPadding)
+		}
+	}
+
+	base := fnt.NewFace(f).Metrics().Ascent.Ceil()
+
+	var (
+		chars     []bmChar
+		x, y      int
+		rowHeight int
+	)
+
+	for i, g := range f.Glyphs {
+		r := fnt.CodePage().DecodeByte(byte(i))
+		if r == utf8.RuneError || g.Type == fnt.GlyphTypeEmpty && g.AdvanceWidth == 0 {
+			continue
+		}
+
+		c := bmChar{
+			ID:       int(r),
+			XAdvance: int(g.AdvanceWidth) + int(f.BaseAdvanceWidth),
+			Channel:  15,
+		}
+
+		if g.Type != fnt.GlyphTypeEmpty && g.Image != nil {
+			size := g.Image.Rect.Size()
+			if x+size.X > width {
+				x = 0
+				y += rowHeight + atlasPadding
+				rowHeight = 0
+			}
+			c.X, c.Y = x, y
+			c.Width, c.Height = size.X, size.Y
+			c.YOffset = base + g.OffsetY()
+
+			x += size.X + atlasPadding
+			rowHeight = max(rowHeight, size.Y)
+		}
+
+		chars = append(chars, c)
+	}
+
+	atlas := image.NewNRGBA(image.Rect(0, 0, width, y+rowHeight))
+
+	for _, c := range chars {
+		if c.Width == 0 {
+			continue
+		}
+		b, _ := fnt.CodePage().EncodeRune(rune(c.ID))
+		drawGlyph(atlas, image.Pt(c.X, c.Y), f.Glyphs[b].Image)
+	}
+
+	d := &bmFont{}
+	d.Info.Face = name
+	d.Info.Size = int(f.LineHeight)
+	d.Info.Unicode = 1
+	d.Info.Spacing = fmt.Sprintf("%d,%d", atlasPadding, atlasPadding)
+	d.Common.LineHeight = int(f.LineHeight)
+	d.Common.Base = base
+	d.Common.ScaleW = atlas.Rect.Dx()
+	d.Common.ScaleH = atlas.Rect.Dy()
+	d.Common.Pages = 1
+	d.Pages = []bmPage{{ID: 0, File: atlasFile}}
+	d.Chars.Count = len(chars)
+	d.Chars.Chars = chars
+
+	return atlas, d
+}
+
+// drawGlyph draws img onto dst at pt, leaving pixels with color index 0
+// transparent.
+func drawGlyph(dst *image.NRGBA, pt image.Point, img *image.Paletted) {
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			if img.ColorIndexAt(x, y) == 0 {
+				continue
+			}
+			p := pt.Add(image.Pt(x, y).Sub(img.Rect.Min))
+			dst.Set(p.X, p.Y, img.At(x, y))
+		}
+	}
+}
+
+// writeText writes d in the BMFont text format.
+func (d *bmFont) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "info face=%q size=%d bold=0 italic=0 charset=\"\" unicode=%d stretchH=100 smooth=0 aa=1 padding=0,0,0,0 spacing=%s\n",
+		d.Info.Face, d.Info.Size, d.Info.Unicode, d.Info.Spacing); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "common lineHeight=%d base=%d scaleW=%d scaleH=%d pages=%d packed=%d\n",
+		d.Common.LineHeight, d.Common.Base, d.Common.ScaleW, d.Common.ScaleH, d.Common.Pages, d.Common.Packed); err != nil {
+		return err
+	}
+	for _, p := range d.Pages {
+		if _, err := fmt.Fprintf(w, "page id=%d file=%q\n", p.ID, p.File); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "chars count=%d\n", d.Chars.Count); err != nil {
+		return err
+	}
+	for _, c := range d.Chars.Chars {
+		if _, err := fmt.Fprintf(w, "char id=%d x=%d y=%d width=%d height=%d xoffset=%d yoffset=%d xadvance=%d page=%d chnl=%d\n",
+			c.ID, c.X, c.Y, c.Width, c.Height, c.XOffset, c.YOffset, c.XAdvance, c.Page, c.Channel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeXML writes d in the BMFont XML format.
+func (d *bmFont) writeXML(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
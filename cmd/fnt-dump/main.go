@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jonathaningram/dark-omen/encoding/fnt"
+)
+
+const (
+	atlasFile      = "atlas.png"
+	descriptorFile = "font.fnt"
+)
+
+func writeAtlas(f *fnt.Font, name, dir, format string) error {
+	atlas, descriptor := buildAtlas(f, name, atlasFile)
+
+	out, err := os.Create(path.Join(dir, atlasFile))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := png.Encode(out, atlas); err != nil {
+		return fmt.Errorf("could not encode PNG file: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("could not sync PNG file: %w", err)
+	}
+
+	out, err = os.Create(path.Join(dir, descriptorFile))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch format {
+	case "xml":
+		err = descriptor.writeXML(out)
+	default:
+		err = descriptor.writeText(out)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write BMFont descriptor: %w", err)
+	}
+
+	return out.Sync()
+}
+
+func main() {
+	const (
+		flagDarkOmenPath = "dark-omen-path"
+		flagOutputPath   = "output-path"
+		flagFormat       = "format"
+	)
+
+	var (
+		darkOmenPath = flag.String(flagDarkOmenPath, "", "path to Dark Omen CD data")
+		outputPath   = flag.String(flagOutputPath, "", "path to directory in which fonts will be dumped")
+		format       = flag.String(flagFormat, "text", "format of the BMFont descriptor, either text or xml")
+	)
+
+	flag.Parse()
+
+	if *darkOmenPath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *outputPath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "xml" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	err := filepath.Walk(*darkOmenPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.ToUpper(path.Ext(info.Name())) != ".FNT" {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		relativePath := strings.TrimPrefix(p, *darkOmenPath)
+
+		fmt.Printf("Decoding %s...", relativePath)
+
+		d := fnt.NewDecoder(f)
+
+		font, err := d.Decode()
+		if err != nil {
+			fmt.Printf("failed\n")
+			return fmt.Errorf("could not decode %s: %w", relativePath, err)
+		}
+
+		fmt.Printf("ok\n")
+
+		dir := path.Join(*outputPath, relativePath)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+
+		fmt.Printf("Creating atlas for %s...", relativePath)
+
+		name := strings.TrimSuffix(info.Name(), path.Ext(info.Name()))
+		if err := writeAtlas(font, name, dir, *format); err != nil {
+			fmt.Printf("failed\n")
+			return fmt.Errorf("could not write atlas for %s: %w", relativePath, err)
+		}
+
+		fmt.Printf("ok\n")
+
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
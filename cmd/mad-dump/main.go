@@ -3,18 +3,26 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/jonathaningram/dark-omen/encoding/mad"
 )
 
-func writeWAV(s *mad.Stream, dir, fileName string) error {
-	file := path.Join(dir, fmt.Sprintf("%s.WAV", fileName))
-	fmt.Printf("Creating WAV %s...", file)
+const (
+	formatWAV  = "wav"
+	formatFLAC = "flac"
+)
+
+func writeAudio(s *mad.Stream, dir, fileName, format string) error {
+	ext := strings.ToUpper(format)
+	file := path.Join(dir, fmt.Sprintf("%s.%s", fileName, ext))
+	fmt.Printf("Creating %s %s...", ext, file)
 	out, err := os.Create(file)
 	if err != nil {
 		fmt.Printf("failed\n")
@@ -22,9 +30,15 @@ func writeWAV(s *mad.Stream, dir, fileName string) error {
 	}
 	defer out.Close()
 
-	if err := s.EncodeToWAV(out); err != nil {
+	switch format {
+	case formatFLAC:
+		err = s.EncodeToFLAC(out)
+	default:
+		err = s.EncodeToWAV(out)
+	}
+	if err != nil {
 		fmt.Printf("failed\n")
-		return fmt.Errorf("could not convert to WAV: %w", err)
+		return fmt.Errorf("could not convert to %s: %w", ext, err)
 	}
 
 	if err := out.Sync(); err != nil {
@@ -35,15 +49,49 @@ func writeWAV(s *mad.Stream, dir, fileName string) error {
 	return nil
 }
 
+// report prints a table of the loudness of every stream to w.
+type report struct {
+	w *tabwriter.Writer
+}
+
+func newReport(w io.Writer) *report {
+	r := &report{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	fmt.Fprintf(r.w, "File\tDuration\tPeak (dBFS)\tRMS (dBFS)\tLoudness (LUFS)\n")
+	return r
+}
+
+func (r *report) add(file string, s *mad.Stream) error {
+	m, err := s.Loudness()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(r.w, "%s\t%.2fs\t%.1f\t%.1f\t%.1f\n", file, s.Duration().Seconds(), m.Peak, m.RMS, m.Integrated)
+	return nil
+}
+
+// fail adds a row for a stream that could not be decoded, so that it is not
+// missing from the report.
+func (r *report) fail(file string, err error) {
+	fmt.Fprintf(r.w, "%s\tfailed: %v\n", file, err)
+}
+
+func (r *report) flush() error {
+	return r.w.Flush()
+}
+
 func main() {
 	const (
 		flagDarkOmenPath = "dark-omen-path"
 		flagOutputPath   = "output-path"
+		flagFormat       = "format"
+		flagReport       = "report"
 	)
 
 	var (
 		darkOmenPath = flag.String(flagDarkOmenPath, "", "path to Dark Omen CD data")
-		outputPath   = flag.String(flagOutputPath, "", "path to directory in which WAV files will be dumped")
+		outputPath   = flag.String(flagOutputPath, "", "path to directory in which audio files will be dumped")
+		format       = flag.String(flagFormat, formatWAV, "format of the dumped audio files: wav or flac")
+		printReport  = flag.Bool(flagReport, false, "print the peak, RMS and integrated loudness of every file instead of dumping them")
 	)
 
 	flag.Parse()
@@ -52,10 +100,19 @@ func main() {
 		flag.Usage()
 		os.Exit(1)
 	}
-	if *outputPath == "" {
+	if *outputPath == "" && !*printReport {
 		flag.Usage()
 		os.Exit(1)
 	}
+	if *format != formatWAV && *format != formatFLAC {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var r *report
+	if *printReport {
+		r = newReport(os.Stdout)
+	}
 
 	err := filepath.Walk(*darkOmenPath, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -73,6 +130,18 @@ func main() {
 
 		relativePath := strings.TrimPrefix(p, *darkOmenPath)
 
+		if r != nil {
+			stream, err := mad.NewDecoder(f).Decode()
+			if err != nil {
+				r.fail(relativePath, err)
+				return nil
+			}
+			if err := r.add(relativePath, stream); err != nil {
+				return fmt.Errorf("could not measure %s: %w", relativePath, err)
+			}
+			return nil
+		}
+
 		fmt.Printf("Decoding %s...", relativePath)
 
 		stream, err := mad.NewDecoder(f).Decode()
@@ -88,8 +157,8 @@ func main() {
 			return err
 		}
 
-		if err := writeWAV(stream, dir, path.Base(strings.TrimSuffix(relativePath, path.Ext(relativePath)))); err != nil {
-			return fmt.Errorf("could not write WAV: %w", err)
+		if err := writeAudio(stream, dir, path.Base(strings.TrimSuffix(relativePath, path.Ext(relativePath))), *format); err != nil {
+			return fmt.Errorf("could not write %s: %w", strings.ToUpper(*format), err)
 		}
 
 		return nil
@@ -97,4 +166,10 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if r != nil {
+		if err := r.flush(); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -12,9 +12,15 @@ import (
 	"github.com/jonathaningram/dark-omen/encoding/sad"
 )
 
-func writeWAV(s *sad.Stream, dir, fileName string) error {
-	file := path.Join(dir, fmt.Sprintf("%s.WAV", fileName))
-	fmt.Printf("Creating WAV %s...", file)
+const (
+	formatWAV  = "wav"
+	formatFLAC = "flac"
+)
+
+func writeAudio(s *sad.Stream, dir, fileName, format string) error {
+	ext := strings.ToUpper(format)
+	file := path.Join(dir, fmt.Sprintf("%s.%s", fileName, ext))
+	fmt.Printf("Creating %s %s...", ext, file)
 	out, err := os.Create(file)
 	if err != nil {
 		fmt.Printf("failed\n")
@@ -22,9 +28,15 @@ func writeWAV(s *sad.Stream, dir, fileName string) error {
 	}
 	defer out.Close()
 
-	if err := s.EncodeToWAV(out); err != nil {
+	switch format {
+	case formatFLAC:
+		err = s.EncodeToFLAC(out)
+	default:
+		err = s.EncodeToWAV(out)
+	}
+	if err != nil {
 		fmt.Printf("failed\n")
-		return fmt.Errorf("could not convert to WAV: %w", err)
+		return fmt.Errorf("could not convert to %s: %w", ext, err)
 	}
 
 	if err := out.Sync(); err != nil {
@@ -39,11 +51,13 @@ func main() {
 	const (
 		flagDarkOmenPath = "dark-omen-path"
 		flagOutputPath   = "output-path"
+		flagFormat       = "format"
 	)
 
 	var (
 		darkOmenPath = flag.String(flagDarkOmenPath, "", "path to Dark Omen CD data")
-		outputPath   = flag.String(flagOutputPath, "", "path to directory in which WAV files will be dumped")
+		outputPath   = flag.String(flagOutputPath, "", "path to directory in which audio files will be dumped")
+		format       = flag.String(flagFormat, formatWAV, "format of the dumped audio files: wav or flac")
 	)
 
 	flag.Parse()
@@ -56,6 +70,10 @@ func main() {
 		flag.Usage()
 		os.Exit(1)
 	}
+	if *format != formatWAV && *format != formatFLAC {
+		flag.Usage()
+		os.Exit(1)
+	}
 
 	err := filepath.Walk(*darkOmenPath, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -88,8 +106,8 @@ func main() {
 			return err
 		}
 
-		if err := writeWAV(stream, dir, path.Base(strings.TrimSuffix(relativePath, path.Ext(relativePath)))); err != nil {
-			return fmt.Errorf("could not write WAV: %w", err)
+		if err := writeAudio(stream, dir, path.Base(strings.TrimSuffix(relativePath, path.Ext(relativePath))), *format); err != nil {
+			return fmt.Errorf("could not write %s: %w", strings.ToUpper(*format), err)
 		}
 
 		return nil
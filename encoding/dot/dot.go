@@ -1,4 +1,4 @@
-// Package dot implements decoding of Dark Omen's .DOT path files.
+// Package dot implements encoding and decoding of Dark Omen's .DOT path files.
 package dot
 
 import (
@@ -28,14 +28,41 @@ type Map struct {
 	// In Dark Omen, the French and German ENGREL.EXE files refer to their own
 	// localized file name so it's likely this is not used.
 	FileName string
+
+	// TODO: Unknown header fields.
+	Unknown1 uint32
+	Unknown2 uint32
+
+	// footer is the footer as it was decoded. It is kept so that the bytes
+	// around FileName are re-encoded as they were read.
+	footer [footerSize]byte
 }
 
 // A Path is made up of a number points at a given x and y coordinate.
 type Path struct {
-	Points   []Point
-	unknown1 uint32 // always 0x05
-	unknown2 uint32 // always 0x0A
-	unknown3 [36]byte
+	Points []Point
+
+	// TODO: Unknown path fields.
+	Unknown1 uint32 // always 0x05
+	Unknown2 uint32 // always 0x0A
+	Unknown3 [36]byte
+
+	// padding is the 8 bytes that follow the coordinates of each point, in the
+	// same order as Points. It is kept so that decoded points are re-encoded as
+	// they were read. The path editing methods keep it in step with Points. If
+	// Points is otherwise changed to a different length, every point is encoded
+	// with zero padding.
+	padding [][8]byte
+}
+
+// NewPath returns a new path made up of points. The unknown fields are set to
+// the values found in every path in the game.
+func NewPath(points ...Point) *Path {
+	return &Path{
+		Points:   points,
+		Unknown1: 0x05,
+		Unknown2: 0x0A,
+	}
 }
 
 // A Point is an x and y coordinate into a Dark Omen map image.
@@ -79,6 +106,9 @@ func (d *Decoder) Decode() (*Map, error) {
 		format:   format,
 		Paths:    paths,
 		FileName: footer.mapFileName,
+		Unknown1: header.unknown1,
+		Unknown2: header.unknown2,
+		footer:   footer.raw,
 	}, nil
 }
 
@@ -127,6 +157,7 @@ func (d *Decoder) readPath() (path *Path, err error) {
 	}
 
 	points := make([]Point, pointCount)
+	padding := make([][8]byte, pointCount)
 	for i := uint32(0); i < pointCount; i++ {
 		var x uint32
 		if err := binary.Read(d.r, binary.LittleEndian, &x); err != nil {
@@ -136,8 +167,7 @@ func (d *Decoder) readPath() (path *Path, err error) {
 		if err := binary.Read(d.r, binary.LittleEndian, &y); err != nil {
 			return nil, err
 		}
-		var padding [8]byte
-		n, err := d.r.Read(padding[:])
+		n, err := d.r.Read(padding[i][:])
 		if n != 8 {
 			return nil, fmt.Errorf("read %d byte(s), expected %d", n, 8)
 		}
@@ -167,14 +197,16 @@ func (d *Decoder) readPath() (path *Path, err error) {
 
 	return &Path{
 		Points:   points,
-		unknown1: unknown1,
-		unknown2: unknown2,
-		unknown3: unknown3,
+		Unknown1: unknown1,
+		Unknown2: unknown2,
+		Unknown3: unknown3,
+		padding:  padding,
 	}, nil
 }
 
 type footer struct {
 	mapFileName string
+	raw         [footerSize]byte
 }
 
 func (d *Decoder) readFooter() (f *footer, err error) {
@@ -189,5 +221,79 @@ func (d *Decoder) readFooter() (f *footer, err error) {
 
 	return &footer{
 		mapFileName: cstringutil.ToGo(buf[footerMapFileOffset:]),
+		raw:         buf,
 	}, nil
 }
+
+// Encoder encodes and writes a DOT file to an output stream.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the encoded map to its output.
+func (e *Encoder) Encode(m *Map) error {
+	footer, err := m.encodeFooter()
+	if err != nil {
+		return fmt.Errorf("could not write footer: %w", err)
+	}
+
+	buf := make([]byte, headerSize, headerSize+footerSize)
+	copy(buf[0:4], format)
+	binary.LittleEndian.PutUint32(buf[4:8], m.Unknown1)
+	binary.LittleEndian.PutUint32(buf[8:12], m.Unknown2)
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(len(m.Paths)))
+
+	for i, p := range m.Paths {
+		if p == nil {
+			return fmt.Errorf("could not write path %d: path is nil", i)
+		}
+		buf = p.appendEncoded(buf)
+	}
+
+	buf = append(buf, footer[:]...)
+
+	_, err = e.w.Write(buf)
+	return err
+}
+
+func (p *Path) appendEncoded(buf []byte) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(p.Points)))
+	keepPadding := len(p.padding) == len(p.Points)
+	for i, pt := range p.Points {
+		buf = binary.LittleEndian.AppendUint32(buf, pt.X)
+		buf = binary.LittleEndian.AppendUint32(buf, pt.Y)
+		var padding [8]byte
+		if keepPadding {
+			padding = p.padding[i]
+		}
+		buf = append(buf, padding[:]...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, p.Unknown1)
+	buf = binary.LittleEndian.AppendUint32(buf, p.Unknown2)
+	return append(buf, p.Unknown3[:]...)
+}
+
+// encodeFooter returns the footer with FileName written into it. The decoded
+// footer is returned unchanged if FileName has not been changed.
+func (m *Map) encodeFooter() ([footerSize]byte, error) {
+	footer := m.footer
+
+	if cstringutil.ToGo(footer[footerMapFileOffset:]) == m.FileName {
+		return footer, nil
+	}
+
+	name := footer[footerMapFileOffset:]
+	// Leave room for the NULL terminator.
+	if limit := len(name) - 1; len(m.FileName) > limit {
+		return footer, fmt.Errorf("file name %q is %d byte(s), expected at most %d", m.FileName, len(m.FileName), limit)
+	}
+	clear(name)
+	copy(name, m.FileName)
+
+	return footer, nil
+}
@@ -0,0 +1,136 @@
+package dot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// testDOT returns an encoded DOT file with two paths, non-zero unknown fields
+// and a footer that has bytes before and after the map file name.
+func testDOT() []byte {
+	buf := &bytes.Buffer{}
+	u32 := func(v uint32) {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+
+	buf.WriteString(format)
+	u32(1)
+	u32(2)
+	u32(2) // path count
+
+	for i, points := range [][][2]uint32{{{10, 20}, {30, 40}, {50, 60}}, {{100, 200}}} {
+		u32(uint32(len(points)))
+		for j, pt := range points {
+			u32(pt[0])
+			u32(pt[1])
+			buf.Write(bytes.Repeat([]byte{byte(j)}, 8))
+		}
+		u32(5)
+		u32(10)
+		buf.Write(bytes.Repeat([]byte{byte(i + 1)}, 36))
+	}
+
+	footer := bytes.Repeat([]byte{0xAA}, footerSize)
+	copy(footer[footerMapFileOffset:], "MAP.BMP\x00\xBB\xBB")
+	buf.Write(footer)
+
+	return buf.Bytes()
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	m, err := NewDecoder(bytes.NewReader(testDOT())).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+
+	if got, want := m.FileName, "MAP.BMP"; got != want {
+		t.Errorf("Map.FileName = %q, want %q", got, want)
+	}
+	if got, want := len(m.Paths), 2; got != want {
+		t.Fatalf("len(Map.Paths) = %d, want %d", got, want)
+	}
+
+	p := m.Paths[0]
+	want := []Point{{X: 10, Y: 20}, {X: 30, Y: 40}, {X: 50, Y: 60}}
+	if diff := cmp.Diff(want, p.Points); diff != "" {
+		t.Errorf("Path.Points mismatch (-want +got):\n%s", diff)
+	}
+	if p.Unknown1 != 5 || p.Unknown2 != 10 {
+		t.Errorf("Path.Unknown1, Path.Unknown2 = %d, %d, want 5, 10", p.Unknown1, p.Unknown2)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := testDOT()
+
+	m, err := NewDecoder(bytes.NewReader(want)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+
+	got := &bytes.Buffer{}
+	if err := NewEncoder(got).Encode(m); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("got encoded bytes = %v, want %v", got.Bytes(), want)
+	}
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	t.Run("new map", func(t *testing.T) {
+		m := &Map{
+			Paths:    []*Path{NewPath(Point{X: 1, Y: 2}, Point{X: 3, Y: 4})},
+			FileName: "NEW.BMP",
+		}
+
+		buf := &bytes.Buffer{}
+		if err := NewEncoder(buf).Encode(m); err != nil {
+			t.Fatalf("Encode() error = %v, want nil", err)
+		}
+		if got, want := buf.Len(), headerSize+4+2*16+4+4+36+footerSize; got != want {
+			t.Errorf("encoded size = %d, want %d", got, want)
+		}
+
+		decoded, err := NewDecoder(buf).Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v, want nil", err)
+		}
+		if diff := cmp.Diff(m, decoded, cmpopts.IgnoreUnexported(Map{}, Path{})); diff != "" {
+			t.Errorf("decoded map mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("renamed map", func(t *testing.T) {
+		m, err := NewDecoder(bytes.NewReader(testDOT())).Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v, want nil", err)
+		}
+		m.FileName = "A.BMP"
+
+		buf := &bytes.Buffer{}
+		if err := NewEncoder(buf).Encode(m); err != nil {
+			t.Fatalf("Encode() error = %v, want nil", err)
+		}
+		decoded, err := NewDecoder(buf).Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v, want nil", err)
+		}
+		if got, want := decoded.FileName, "A.BMP"; got != want {
+			t.Errorf("Map.FileName = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("file name too long", func(t *testing.T) {
+		m := &Map{FileName: strings.Repeat("A", 72)}
+		err := NewEncoder(&bytes.Buffer{}).Encode(m)
+		if want := "could not write footer: file name"; err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("Encode() error = %v, want prefix %q", err, want)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package dot
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// geoJSONMap is a GeoJSON FeatureCollection with the map's own fields stored
+// as foreign members.
+type geoJSONMap struct {
+	Type     string           `json:"type"`
+	FileName string           `json:"fileName,omitempty"`
+	Unknown1 uint32           `json:"unknown1"`
+	Unknown2 uint32           `json:"unknown2"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   *geoJSONGeometry  `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONProperties struct {
+	Index      *int    `json:"index,omitempty"`
+	PointCount int     `json:"pointCount"`
+	Unknown1   *uint32 `json:"unknown1,omitempty"`
+	Unknown2   *uint32 `json:"unknown2,omitempty"`
+	Unknown3   string  `json:"unknown3,omitempty"`
+}
+
+// WriteGeoJSON writes the map's paths to w as a GeoJSON FeatureCollection.
+// Each path is a LineString feature, or a Point feature if it only has one
+// point, whose properties are the path's index, point count and attributes.
+//
+// Coordinates are the pixel coordinates of the map bitmap, [x, y], with y
+// increasing downwards. To display them with Leaflet's CRS.Simple, which has y
+// increasing upwards, negate y when converting coordinates to a LatLng.
+func WriteGeoJSON(w io.Writer, m *Map) error {
+	fc := geoJSONMap{
+		Type:     "FeatureCollection",
+		FileName: m.FileName,
+		Unknown1: m.Unknown1,
+		Unknown2: m.Unknown2,
+		Features: make([]geoJSONFeature, len(m.Paths)),
+	}
+
+	for i, p := range m.Paths {
+		f := geoJSONFeature{
+			Type: "Feature",
+			Properties: geoJSONProperties{
+				Index:      &i,
+				PointCount: len(p.Points),
+				Unknown1:   &p.Unknown1,
+				Unknown2:   &p.Unknown2,
+				Unknown3:   hex.EncodeToString(p.Unknown3[:]),
+			},
+		}
+
+		coordinates := make([][2]uint32, len(p.Points))
+		for j, pt := range p.Points {
+			coordinates[j] = [2]uint32{pt.X, pt.Y}
+		}
+
+		var err error
+		switch len(coordinates) {
+		case 0:
+		case 1:
+			f.Geometry = &geoJSONGeometry{Type: "Point"}
+			f.Geometry.Coordinates, err = json.Marshal(coordinates[0])
+		default:
+			f.Geometry = &geoJSONGeometry{Type: "LineString"}
+			f.Geometry.Coordinates, err = json.Marshal(coordinates)
+		}
+		if err != nil {
+			return fmt.Errorf("could not write path %d: %w", i, err)
+		}
+
+		fc.Features[i] = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(fc)
+}
+
+// ReadGeoJSON reads a GeoJSON FeatureCollection from r, such as one written by
+// WriteGeoJSON, and returns a map containing a path for each LineString or
+// Point feature. Coordinates are rounded to the nearest pixel.
+//
+// If every feature has an index property, paths are ordered by it. Otherwise
+// they are in the order of the features. Path attributes that are missing
+// from a feature's properties are set to the values used by NewPath.
+func ReadGeoJSON(r io.Reader) (*Map, error) {
+	var fc geoJSONMap
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, err
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("unknown GeoJSON type %q, expected %q", fc.Type, "FeatureCollection")
+	}
+
+	indexed := true
+	for _, f := range fc.Features {
+		if f.Properties.Index == nil {
+			indexed = false
+			break
+		}
+	}
+	if indexed {
+		slices.SortStableFunc(fc.Features, func(a, b geoJSONFeature) int {
+			return *a.Properties.Index - *b.Properties.Index
+		})
+	}
+
+	m := &Map{
+		format:   format,
+		Paths:    make([]*Path, len(fc.Features)),
+		FileName: fc.FileName,
+		Unknown1: fc.Unknown1,
+		Unknown2: fc.Unknown2,
+	}
+
+	for i, f := range fc.Features {
+		p, err := f.path()
+		if err != nil {
+			return nil, fmt.Errorf("could not read feature %d: %w", i, err)
+		}
+		m.Paths[i] = p
+	}
+
+	return m, nil
+}
+
+func (f *geoJSONFeature) path() (*Path, error) {
+	p := NewPath()
+
+	if v := f.Properties.Unknown1; v != nil {
+		p.Unknown1 = *v
+	}
+	if v := f.Properties.Unknown2; v != nil {
+		p.Unknown2 = *v
+	}
+	if v := f.Properties.Unknown3; v != "" {
+		bs, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unknown3 property: %w", err)
+		}
+		if len(bs) != len(p.Unknown3) {
+			return nil, fmt.Errorf("unknown3 property is %d byte(s), expected %d", len(bs), len(p.Unknown3))
+		}
+		copy(p.Unknown3[:], bs)
+	}
+
+	if f.Geometry == nil {
+		return p, nil
+	}
+
+	var coordinates [][]float64
+	switch f.Geometry.Type {
+	case "Point":
+		var c []float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &c); err != nil {
+			return nil, fmt.Errorf("invalid Point coordinates: %w", err)
+		}
+		coordinates = [][]float64{c}
+	case "LineString":
+		if err := json.Unmarshal(f.Geometry.Coordinates, &coordinates); err != nil {
+			return nil, fmt.Errorf("invalid LineString coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q, expected %q or %q", f.Geometry.Type, "LineString", "Point")
+	}
+
+	p.Points = make([]Point, len(coordinates))
+	for i, c := range coordinates {
+		if len(c) < 2 {
+			return nil, fmt.Errorf("position %d has %d value(s), expected at least 2", i, len(c))
+		}
+		x, err := pixel(c[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid x at position %d: %w", i, err)
+		}
+		y, err := pixel(c[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid y at position %d: %w", i, err)
+		}
+		p.Points[i] = Point{X: x, Y: y}
+	}
+
+	return p, nil
+}
+
+// pixel returns v rounded to the nearest pixel coordinate.
+func pixel(v float64) (uint32, error) {
+	v = math.Round(v)
+	if v < 0 || v > math.MaxUint32 || math.IsNaN(v) {
+		return 0, fmt.Errorf("%g is outside of the map", v)
+	}
+	return uint32(v), nil
+}
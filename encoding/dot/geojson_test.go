@@ -0,0 +1,104 @@
+package dot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestGeoJSON_roundTrip(t *testing.T) {
+	want, err := NewDecoder(bytes.NewReader(testDOT())).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteGeoJSON(buf, want); err != nil {
+		t.Fatalf("WriteGeoJSON() error = %v, want nil", err)
+	}
+	for _, s := range []string{`"type": "LineString"`, `"type": "Point"`, `"pointCount": 3`, `"fileName": "MAP.BMP"`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("WriteGeoJSON() output does not contain %s", s)
+		}
+	}
+
+	got, err := ReadGeoJSON(buf)
+	if err != nil {
+		t.Fatalf("ReadGeoJSON() error = %v, want nil", err)
+	}
+	// The padding of each point and the footer are not part of the GeoJSON.
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(Map{}, Path{})); diff != "" {
+		t.Errorf("ReadGeoJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadGeoJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    *Map
+		wantErr string
+	}{
+		{
+			name: "drawn in a web editor",
+			json: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "properties": {}, "geometry": {"type": "LineString", "coordinates": [[10.4, 20.6], [30, 40]]}},
+				{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [5, 6]}}
+			]}`,
+			want: &Map{
+				Paths: []*Path{
+					NewPath(Point{X: 10, Y: 21}, Point{X: 30, Y: 40}),
+					NewPath(Point{X: 5, Y: 6}),
+				},
+			},
+		},
+		{
+			name: "ordered by index",
+			json: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "properties": {"index": 1}, "geometry": {"type": "Point", "coordinates": [1, 1]}},
+				{"type": "Feature", "properties": {"index": 0}, "geometry": {"type": "Point", "coordinates": [0, 0]}}
+			]}`,
+			want: &Map{
+				Paths: []*Path{
+					NewPath(Point{X: 0, Y: 0}),
+					NewPath(Point{X: 1, Y: 1}),
+				},
+			},
+		},
+		{
+			name:    "negative coordinate",
+			json:    `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [1, -10]}}]}`,
+			wantErr: "could not read feature 0: invalid y at position 0: -10 is outside of the map",
+		},
+		{
+			name:    "unsupported geometry",
+			json:    `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": {"type": "Polygon", "coordinates": []}}]}`,
+			wantErr: `could not read feature 0: unsupported geometry type "Polygon", expected "LineString" or "Point"`,
+		},
+		{
+			name:    "not a feature collection",
+			json:    `{"type": "Feature"}`,
+			wantErr: `unknown GeoJSON type "Feature", expected "FeatureCollection"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadGeoJSON(strings.NewReader(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ReadGeoJSON() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadGeoJSON() error = %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreUnexported(Map{}, Path{})); diff != "" {
+				t.Errorf("ReadGeoJSON() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package dot
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// InsertPoint inserts pt into the path at index i, shifting the points at and
+// after i along. i may be len(p.Points) to append pt to the end of the path.
+func (p *Path) InsertPoint(i int, pt Point) error {
+	if i < 0 || i > len(p.Points) {
+		return fmt.Errorf("point index %d out of range [0,%d]", i, len(p.Points))
+	}
+	if p.hasPadding() {
+		p.padding = slices.Insert(p.padding, i, [8]byte{})
+	}
+	p.Points = slices.Insert(p.Points, i, pt)
+	return nil
+}
+
+// RemovePoint removes the point at index i from the path.
+func (p *Path) RemovePoint(i int) error {
+	if i < 0 || i >= len(p.Points) {
+		return fmt.Errorf("point index %d out of range [0,%d)", i, len(p.Points))
+	}
+	if p.hasPadding() {
+		p.padding = slices.Delete(p.padding, i, i+1)
+	}
+	p.Points = slices.Delete(p.Points, i, i+1)
+	return nil
+}
+
+// MovePoint moves the point at index from to index to, shifting the points in
+// between.
+func (p *Path) MovePoint(from, to int) error {
+	if from < 0 || from >= len(p.Points) {
+		return fmt.Errorf("point index %d out of range [0,%d)", from, len(p.Points))
+	}
+	if to < 0 || to >= len(p.Points) {
+		return fmt.Errorf("point index %d out of range [0,%d)", to, len(p.Points))
+	}
+	if p.hasPadding() {
+		p.padding = move(p.padding, from, to)
+	}
+	p.Points = move(p.Points, from, to)
+	return nil
+}
+
+func move[S ~[]E, E any](s S, from, to int) S {
+	e := s[from]
+	return slices.Insert(slices.Delete(s, from, from+1), to, e)
+}
+
+// Reverse reverses the order of the points in the path, so that it runs from
+// its last point to its first.
+func (p *Path) Reverse() {
+	if p.hasPadding() {
+		slices.Reverse(p.padding)
+	}
+	slices.Reverse(p.Points)
+}
+
+// hasPadding reports whether the path has the padding of each of its points.
+func (p *Path) hasPadding() bool {
+	return p.padding != nil && len(p.padding) == len(p.Points)
+}
+
+// Length returns the length of the path, in pixels, which is the sum of the
+// distances between each of its points.
+func (p *Path) Length() float64 {
+	var length float64
+	for i := 1; i < len(p.Points); i++ {
+		length += distance(p.Points[i-1], p.Points[i])
+	}
+	return length
+}
+
+// PointAt returns the coordinates of the point that is a fraction t of the way
+// along the path, measured by distance along the path rather than by point.
+// For example, PointAt(0.5) is half of Length() from the start of the path even
+// if most of its points are near the start. t is clamped to [0, 1]. It returns
+// 0, 0 for a path with no points.
+func (p *Path) PointAt(t float64) (x, y float64) {
+	if len(p.Points) == 0 {
+		return 0, 0
+	}
+
+	t = math.Max(0, math.Min(1, t))
+	remaining := t * p.Length()
+
+	for i := 1; i < len(p.Points); i++ {
+		a, b := p.Points[i-1], p.Points[i]
+		d := distance(a, b)
+		if remaining <= d && d > 0 {
+			return lerp(a, b, remaining/d)
+		}
+		remaining -= d
+	}
+
+	last := p.Points[len(p.Points)-1]
+	return float64(last.X), float64(last.Y)
+}
+
+// Resample returns a new path whose points are evenly spaced along p, spacing
+// pixels apart, starting at the first point of p and ending at its last point.
+// The last gap may be shorter than spacing. Points are rounded to the nearest
+// pixel and the path's unknown fields are copied from p.
+//
+// Every path in the game has an Unknown2 of 10, which may be the spacing of
+// the dots that the game draws along the path.
+func (p *Path) Resample(spacing float64) *Path {
+	resampled := &Path{
+		Unknown1: p.Unknown1,
+		Unknown2: p.Unknown2,
+		Unknown3: p.Unknown3,
+	}
+	if len(p.Points) == 0 {
+		return resampled
+	}
+
+	walkPath(p, spacing, func(x, y float64) {
+		resampled.Points = append(resampled.Points, Point{
+			X: uint32(math.Round(x)),
+			Y: uint32(math.Round(y)),
+		})
+	})
+
+	last := p.Points[len(p.Points)-1]
+	if end := resampled.Points[len(resampled.Points)-1]; end.X != last.X || end.Y != last.Y {
+		resampled.Points = append(resampled.Points, Point{X: last.X, Y: last.Y})
+	}
+
+	return resampled
+}
+
+func distance(a, b Point) float64 {
+	return math.Hypot(float64(b.X)-float64(a.X), float64(b.Y)-float64(a.Y))
+}
+
+// lerp returns the coordinates of the point a fraction t of the way from a to
+// b.
+func lerp(a, b Point, t float64) (x, y float64) {
+	x = float64(a.X) + t*(float64(b.X)-float64(a.X))
+	y = float64(a.Y) + t*(float64(b.Y)-float64(a.Y))
+	return x, y
+}
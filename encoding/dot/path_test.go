@@ -0,0 +1,174 @@
+package dot
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestPath_editing(t *testing.T) {
+	a, b, c, d := Point{X: 1}, Point{X: 2}, Point{X: 3}, Point{X: 4}
+
+	tests := []struct {
+		name    string
+		edit    func(p *Path) error
+		want    []Point
+		wantErr bool
+	}{
+		{
+			name: "insert at start",
+			edit: func(p *Path) error { return p.InsertPoint(0, d) },
+			want: []Point{d, a, b, c},
+		},
+		{
+			name: "insert at end",
+			edit: func(p *Path) error { return p.InsertPoint(3, d) },
+			want: []Point{a, b, c, d},
+		},
+		{
+			name:    "insert out of range",
+			edit:    func(p *Path) error { return p.InsertPoint(4, d) },
+			want:    []Point{a, b, c},
+			wantErr: true,
+		},
+		{
+			name: "remove",
+			edit: func(p *Path) error { return p.RemovePoint(1) },
+			want: []Point{a, c},
+		},
+		{
+			name:    "remove out of range",
+			edit:    func(p *Path) error { return p.RemovePoint(3) },
+			want:    []Point{a, b, c},
+			wantErr: true,
+		},
+		{
+			name: "move forwards",
+			edit: func(p *Path) error { return p.MovePoint(0, 2) },
+			want: []Point{b, c, a},
+		},
+		{
+			name: "move backwards",
+			edit: func(p *Path) error { return p.MovePoint(2, 0) },
+			want: []Point{c, a, b},
+		},
+		{
+			name:    "move out of range",
+			edit:    func(p *Path) error { return p.MovePoint(0, -1) },
+			want:    []Point{a, b, c},
+			wantErr: true,
+		},
+		{
+			name: "reverse",
+			edit: func(p *Path) error { p.Reverse(); return nil },
+			want: []Point{c, b, a},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPath(a, b, c)
+			// Each point's padding starts with its X, as if it had been decoded.
+			p.padding = [][8]byte{{1}, {2}, {3}}
+			if err := tt.edit(p); (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, p.Points); diff != "" {
+				t.Errorf("Path.Points mismatch (-want +got):\n%s", diff)
+			}
+			// Inserted points have zero padding.
+			wantPadding := make([][8]byte, len(tt.want))
+			for i, pt := range tt.want {
+				if pt != d {
+					wantPadding[i][0] = byte(pt.X)
+				}
+			}
+			if diff := cmp.Diff(wantPadding, p.padding); diff != "" {
+				t.Errorf("Path.padding mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPath_Length(t *testing.T) {
+	tests := []struct {
+		name string
+		path *Path
+		want float64
+	}{
+		{name: "empty", path: NewPath(), want: 0},
+		{name: "single point", path: NewPath(Point{X: 5, Y: 5}), want: 0},
+		{name: "one segment", path: NewPath(Point{X: 0, Y: 0}, Point{X: 3, Y: 4}), want: 5},
+		{
+			name: "two segments",
+			path: NewPath(Point{X: 0, Y: 0}, Point{X: 10, Y: 0}, Point{X: 10, Y: 20}),
+			want: 30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.path.Length(); got != tt.want {
+				t.Errorf("Path.Length() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPath_PointAt(t *testing.T) {
+	// The first segment is 10 pixels long and the second is 30 pixels long so
+	// that distance along the path differs from the fraction of points.
+	p := NewPath(Point{X: 0, Y: 0}, Point{X: 10, Y: 0}, Point{X: 10, Y: 30})
+
+	tests := []struct {
+		t     float64
+		wantX float64
+		wantY float64
+	}{
+		{t: -1, wantX: 0, wantY: 0},
+		{t: 0, wantX: 0, wantY: 0},
+		{t: 0.125, wantX: 5, wantY: 0},
+		{t: 0.25, wantX: 10, wantY: 0},
+		{t: 0.5, wantX: 10, wantY: 10},
+		{t: 1, wantX: 10, wantY: 30},
+		{t: 2, wantX: 10, wantY: 30},
+	}
+	for _, tt := range tests {
+		x, y := p.PointAt(tt.t)
+		if x != tt.wantX || y != tt.wantY {
+			t.Errorf("Path.PointAt(%v) = %v, %v, want %v, %v", tt.t, x, y, tt.wantX, tt.wantY)
+		}
+	}
+
+	if x, y := NewPath().PointAt(0.5); x != 0 || y != 0 {
+		t.Errorf("empty Path.PointAt(0.5) = %v, %v, want 0, 0", x, y)
+	}
+}
+
+func TestPath_Resample(t *testing.T) {
+	p := NewPath(Point{X: 0, Y: 0}, Point{X: 3, Y: 0}, Point{X: 25, Y: 0})
+	p.Unknown3[0] = 1
+
+	got := p.Resample(10)
+
+	want := NewPath(
+		Point{X: 0, Y: 0},
+		Point{X: 10, Y: 0},
+		Point{X: 20, Y: 0},
+		Point{X: 25, Y: 0},
+	)
+	want.Unknown3[0] = 1
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(Path{})); diff != "" {
+		t.Errorf("Path.Resample() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The end point is not repeated when it falls on the spacing.
+	got = NewPath(Point{X: 0, Y: 0}, Point{X: 0, Y: 20}).Resample(10)
+	wantPoints := []Point{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 0, Y: 20}}
+	if diff := cmp.Diff(wantPoints, got.Points); diff != "" {
+		t.Errorf("Path.Resample() points mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := NewPath().Resample(10); len(got.Points) != 0 {
+		t.Errorf("empty Path.Resample() has %d point(s), want 0", len(got.Points))
+	}
+}
@@ -0,0 +1,123 @@
+package dot
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Style configures how paths are drawn by Render.
+type Style struct {
+	// Color is the color of the dots along each path.
+	Color color.Color
+	// Radius is the radius of each dot, in pixels.
+	Radius int
+	// Spacing is the distance between the centers of neighboring dots, in
+	// pixels.
+	Spacing float64
+	// MarkerColor is the color of the markers drawn at the start and end of
+	// each path. If nil, no markers are drawn.
+	MarkerColor color.Color
+	// MarkerRadius is the radius of the markers, in pixels.
+	MarkerRadius int
+}
+
+// DefaultStyle returns the style used when none is given, which draws paths as
+// a trail of small red dots with a yellow marker at each end. The colors are
+// arbitrary and do not come from the game.
+func DefaultStyle() *Style {
+	return &Style{
+		Color:        color.NRGBA{R: 0x8B, G: 0x0B, B: 0x0B, A: 0xFF},
+		Radius:       2,
+		Spacing:      10,
+		MarkerColor:  color.NRGBA{R: 0xE0, G: 0xC0, B: 0x40, A: 0xFF},
+		MarkerRadius: 4,
+	}
+}
+
+// dotColor returns the color of the dots, or the color of DefaultStyle if
+// s.Color is nil.
+func (s *Style) dotColor() color.Color {
+	if s.Color == nil {
+		return DefaultStyle().Color
+	}
+	return s.Color
+}
+
+// Render draws each of the map's paths onto dst. Path points are pixel
+// coordinates into the map bitmap named by m.FileName, so dst would normally
+// already contain that bitmap. If style is nil, DefaultStyle is used, and if
+// style.Color is nil, the color of DefaultStyle is used. Nil paths are skipped.
+func Render(dst draw.Image, m *Map, style *Style) {
+	if style == nil {
+		style = DefaultStyle()
+	}
+
+	dot := disc(style.Radius)
+	marker := disc(style.MarkerRadius)
+
+	for _, p := range m.Paths {
+		if p == nil || len(p.Points) == 0 {
+			continue
+		}
+
+		src := image.NewUniform(style.dotColor())
+		walkPath(p, style.Spacing, func(x, y float64) {
+			drawDisc(dst, src, dot, x, y)
+		})
+
+		if style.MarkerColor == nil {
+			continue
+		}
+		src = image.NewUniform(style.MarkerColor)
+		first, last := p.Points[0], p.Points[len(p.Points)-1]
+		drawDisc(dst, src, marker, float64(first.X), float64(first.Y))
+		drawDisc(dst, src, marker, float64(last.X), float64(last.Y))
+	}
+}
+
+// walkPath calls fn at each point along p that is a multiple of spacing pixels
+// from its start, including the start itself.
+func walkPath(p *Path, spacing float64, fn func(x, y float64)) {
+	if spacing <= 0 {
+		spacing = 1
+	}
+
+	start := p.Points[0]
+	fn(float64(start.X), float64(start.Y))
+
+	// next is the distance along the current segment to the next dot.
+	next := spacing
+	for i := 1; i < len(p.Points); i++ {
+		a, b := p.Points[i-1], p.Points[i]
+		length := distance(a, b)
+
+		for ; next <= length; next += spacing {
+			fn(lerp(a, b, next/length))
+		}
+		next -= length
+	}
+}
+
+// disc returns an alpha mask of a filled circle with radius r centered on the
+// origin.
+func disc(r int) *image.Alpha {
+	r = max(r, 0)
+	mask := image.NewAlpha(image.Rect(-r, -r, r+1, r+1))
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r+r {
+				mask.SetAlpha(x, y, color.Alpha{A: 0xFF})
+			}
+		}
+	}
+	return mask
+}
+
+// drawDisc draws src through mask centered at x, y.
+func drawDisc(dst draw.Image, src image.Image, mask *image.Alpha, x, y float64) {
+	center := image.Pt(int(math.Round(x)), int(math.Round(y)))
+	r := mask.Rect.Add(center)
+	draw.DrawMask(dst, r, src, image.Point{}, mask, mask.Rect.Min, draw.Over)
+}
@@ -0,0 +1,86 @@
+package dot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	m := &Map{
+		Paths: []*Path{
+			NewPath(Point{X: 10, Y: 10}, Point{X: 25, Y: 10}, Point{X: 25, Y: 25}),
+		},
+	}
+
+	dotColor := color.NRGBA{R: 0xFF, A: 0xFF}
+	markerColor := color.NRGBA{B: 0xFF, A: 0xFF}
+
+	tests := []struct {
+		name  string
+		style *Style
+		want  map[image.Point]color.NRGBA
+	}{
+		{
+			name:  "dots",
+			style: &Style{Color: dotColor, Radius: 1, Spacing: 10},
+			want: map[image.Point]color.NRGBA{
+				{X: 10, Y: 10}: dotColor,
+				{X: 15, Y: 10}: {},
+				{X: 20, Y: 10}: dotColor,
+				// The path turns the corner at (25,10) so the next dot is 10
+				// pixels further along the path.
+				{X: 25, Y: 10}: {},
+				{X: 25, Y: 15}: dotColor,
+				{X: 25, Y: 25}: dotColor,
+			},
+		},
+		{
+			name: "markers",
+			style: &Style{
+				Color:        dotColor,
+				Radius:       1,
+				Spacing:      10,
+				MarkerColor:  markerColor,
+				MarkerRadius: 3,
+			},
+			want: map[image.Point]color.NRGBA{
+				{X: 10, Y: 10}: markerColor,
+				{X: 12, Y: 10}: markerColor,
+				{X: 20, Y: 10}: dotColor,
+				{X: 25, Y: 25}: markerColor,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+			Render(dst, m, tt.style)
+			for pt, want := range tt.want {
+				if got := dst.NRGBAAt(pt.X, pt.Y); got != want {
+					t.Errorf("pixel at %v = %v, want %v", pt, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_nil(t *testing.T) {
+	// Nil paths are skipped and a nil color falls back to the default style.
+	m := &Map{
+		Paths: []*Path{
+			nil,
+			NewPath(Point{X: 10, Y: 10}, Point{X: 20, Y: 10}),
+		},
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	Render(dst, m, &Style{Radius: 1, Spacing: 10})
+
+	want := color.NRGBAModel.Convert(DefaultStyle().Color).(color.NRGBA)
+	for _, pt := range []image.Point{{X: 10, Y: 10}, {X: 20, Y: 10}} {
+		if got := dst.NRGBAAt(pt.X, pt.Y); got != want {
+			t.Errorf("pixel at %v = %v, want %v", pt, got, want)
+		}
+	}
+}
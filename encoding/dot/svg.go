@@ -0,0 +1,71 @@
+package dot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// WriteSVG writes the map's paths to w as an SVG image of the given size. Each
+// path is a polyline drawn as a trail of dots in the given style, with its
+// index, point count and attributes in data attributes. If size is zero, the
+// image is just large enough to contain every path. If style is nil,
+// DefaultStyle is used, and if style.Color is nil, the color of DefaultStyle is
+// used. Nil paths are skipped.
+func WriteSVG(w io.Writer, m *Map, size image.Point, style *Style) error {
+	if style == nil {
+		style = DefaultStyle()
+	}
+	if size == (image.Point{}) {
+		for _, p := range m.Paths {
+			if p == nil {
+				continue
+			}
+			for _, pt := range p.Points {
+				size.X = max(size.X, int(pt.X)+style.MarkerRadius+style.Radius+1)
+				size.Y = max(size.Y, int(pt.Y)+style.MarkerRadius+style.Radius+1)
+			}
+		}
+	}
+
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size.X, size.Y, size.X, size.Y)
+
+	for i, p := range m.Paths {
+		if p == nil {
+			continue
+		}
+		points := make([]string, len(p.Points))
+		for j, pt := range p.Points {
+			points[j] = fmt.Sprintf("%d,%d", pt.X, pt.Y)
+		}
+
+		fmt.Fprintf(b, "\t"+`<g id="path-%d" data-index="%d" data-points="%d" data-unknown1="%d" data-unknown2="%d">`+"\n", i, i, len(p.Points), p.Unknown1, p.Unknown2)
+		fmt.Fprintf(b, "\t\t"+`<polyline points="%s" fill="none" stroke="%s" stroke-width="%d" stroke-linecap="round" stroke-dasharray="0 %g"/>`+"\n", strings.Join(points, " "), svgColor(style.dotColor()), 2*style.Radius+1, style.Spacing)
+
+		if style.MarkerColor != nil && len(p.Points) > 0 {
+			for _, pt := range []Point{p.Points[0], p.Points[len(p.Points)-1]} {
+				fmt.Fprintf(b, "\t\t"+`<circle cx="%d" cy="%d" r="%d" fill="%s"/>`+"\n", pt.X, pt.Y, style.MarkerRadius, svgColor(style.MarkerColor))
+			}
+		}
+
+		b.WriteString("\t</g>\n")
+	}
+
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// svgColor returns c as an SVG color value.
+func svgColor(c color.Color) string {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if nrgba.A == 0xFF {
+		return fmt.Sprintf("#%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%.3g)", nrgba.R, nrgba.G, nrgba.B, float64(nrgba.A)/0xFF)
+}
@@ -0,0 +1,68 @@
+package dot
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestWriteSVG(t *testing.T) {
+	m := &Map{
+		Paths: []*Path{
+			NewPath(Point{X: 10, Y: 20}, Point{X: 30, Y: 40}),
+			// Nil paths are skipped.
+			nil,
+		},
+	}
+
+	tests := []struct {
+		name  string
+		size  image.Point
+		style *Style
+		want  []string
+	}{
+		{
+			name: "default style",
+			want: []string{
+				`width="37" height="47"`,
+				`<g id="path-0" data-index="0" data-points="2" data-unknown1="5" data-unknown2="10">`,
+				`<polyline points="10,20 30,40" fill="none" stroke="#8b0b0b" stroke-width="5" stroke-linecap="round" stroke-dasharray="0 10"/>`,
+				`<circle cx="10" cy="20" r="4" fill="#e0c040"/>`,
+				`<circle cx="30" cy="40" r="4" fill="#e0c040"/>`,
+			},
+		},
+		{
+			name:  "sized without markers",
+			size:  image.Pt(640, 480),
+			style: &Style{Color: DefaultStyle().Color, Radius: 1, Spacing: 4},
+			want: []string{
+				`width="640" height="480" viewBox="0 0 640 480"`,
+				`stroke-width="3" stroke-linecap="round" stroke-dasharray="0 4"/>`,
+			},
+		},
+		{
+			name:  "nil color",
+			style: &Style{Radius: 1, Spacing: 4},
+			want: []string{
+				`stroke="#8b0b0b" stroke-width="3"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := WriteSVG(buf, m, tt.size, tt.style); err != nil {
+				t.Fatalf("WriteSVG() error = %v, want nil", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("WriteSVG() output does not contain %s, got:\n%s", s, buf)
+				}
+			}
+			if tt.style != nil && tt.style.MarkerColor == nil && strings.Contains(buf.String(), "<circle") {
+				t.Errorf("WriteSVG() output contains markers, want none")
+			}
+		})
+	}
+}
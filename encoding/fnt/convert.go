@@ -0,0 +1,165 @@
+package fnt
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"unicode/utf8"
+
+	"github.com/jonathaningram/dark-omen/internal/bdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultPalette returns the color table used when converting a font without a
+// palette. Index 0 is magenta, which is the transparent background color used
+// by the game's fonts, and indices 1 to 15 are a gray ramp from dark to white.
+func DefaultPalette() color.Palette {
+	p := make(color.Palette, colorTableColorCount)
+	p[0] = color.RGBA{R: 0xff, B: 0xff, A: 0xff}
+	for i := 1; i < colorTableColorCount; i++ {
+		y := uint8(i * 0xff / (colorTableColorCount - 1))
+		p[i] = color.RGBA{R: y, G: y, B: y, A: 0xff}
+	}
+	return p
+}
+
+// ConvertOptions configures how a font is rasterized into a Font.
+type ConvertOptions struct {
+	// Palette is the color table of the converted font, which is used for
+	// both Font.ColorTable1 and Font.ColorTable2. It must have 16 colors.
+	// Index 0 is the transparent background and indices 1 to 15 are used for
+	// increasing glyph coverage, so anti-aliased edges use the low indices
+	// and solid strokes use index 15. If nil, DefaultPalette() is used.
+	Palette color.Palette
+}
+
+// FromTrueType rasterizes the TrueType or OpenType font in data at size
+// points, at 72 DPI, into a new Font. opts may be nil.
+func FromTrueType(data []byte, size float64, opts *ConvertOptions) (*Font, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse font: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	return FromFace(face, opts)
+}
+
+// FromBDF rasterizes the BDF font read from r into a new Font. The glyph
+// encodings of the BDF font are assumed to be Unicode code points. opts may be
+// nil.
+func FromBDF(r io.Reader, opts *ConvertOptions) (*Font, error) {
+	face, err := bdf.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse font: %w", err)
+	}
+	return FromFace(face, opts)
+}
+
+// FromFace rasterizes face into a new Font. Each of the 256 glyphs is the
+// face's glyph for the rune at that position in CodePage. Glyph coverage is
+// quantized to the 16 colors of the palette. opts may be nil.
+//
+// Glyphs cannot be offset on the x-axis, so any part of a glyph that is left
+// of its origin is shifted right. Font.Unknown1 is left as 0.
+func FromFace(face font.Face, opts *ConvertOptions) (*Font, error) {
+	if opts == nil {
+		opts = &ConvertOptions{}
+	}
+	palette := opts.Palette
+	if palette == nil {
+		palette = DefaultPalette()
+	}
+	if n := len(palette); n != colorTableColorCount {
+		return nil, fmt.Errorf("palette has %d color(s), expected %d", n, colorTableColorCount)
+	}
+
+	m := face.Metrics()
+	height2 := m.Ascent.Ceil() + m.Descent.Ceil()
+	baseAdvanceHeight := max(0, m.Height.Ceil()-height2)
+
+	f := &Font{
+		format:            format,
+		ColorTable1:       palette,
+		ColorTable2:       palette,
+		LineHeight:        uint32(baseAdvanceHeight + height2),
+		BaseAdvanceHeight: uint16(baseAdvanceHeight),
+		Height2:           uint16(height2),
+		Glyphs:            make([]*Glyph, glyphCount),
+	}
+
+	for i := range f.Glyphs {
+		g, err := rasterizeGlyph(face, i, palette)
+		if err != nil {
+			return nil, fmt.Errorf("could not rasterize glyph %d: %w", i, err)
+		}
+		f.Glyphs[i] = g
+	}
+
+	return f, nil
+}
+
+// rasterizeGlyph returns the glyph at index i of CodePage rasterized from
+// face.
+func rasterizeGlyph(face font.Face, i int, palette color.Palette) (*Glyph, error) {
+	empty := &Glyph{Type: GlyphTypeEmpty}
+
+	r := CodePage().DecodeByte(byte(i))
+	if r < ' ' || r == utf8.RuneError {
+		return empty, nil
+	}
+
+	dr, mask, maskp, advance, ok := face.Glyph(fixed.Point26_6{}, r)
+	if !ok {
+		return empty, nil
+	}
+	advanceWidth := uint16(max(0, advance.Round()))
+	if dr.Empty() {
+		empty.AdvanceWidth = advanceWidth
+		return empty, nil
+	}
+
+	left := min(0, dr.Min.X)
+	width := dr.Max.X - left
+	if width%2 != 0 {
+		width++
+	}
+	if width > 0xFFFF || dr.Dy() > 0xFFFF {
+		return nil, fmt.Errorf("glyph size %dx%d is too large", width, dr.Dy())
+	}
+
+	coverage := image.NewAlpha(image.Rect(0, 0, width, dr.Dy()))
+	draw.DrawMask(
+		coverage, dr.Sub(image.Pt(left, dr.Min.Y)),
+		image.Opaque, image.Point{},
+		mask, maskp,
+		draw.Src,
+	)
+
+	img := image.NewPaletted(coverage.Rect, palette)
+	for j, a := range coverage.Pix {
+		img.Pix[j] = uint8((int(a)*(colorTableColorCount-1) + 0x7f) / 0xff)
+	}
+
+	offsetY := int16(dr.Min.Y)
+
+	return &Glyph{
+		Type:         GlyphTypeNormal,
+		Image:        img,
+		AdvanceWidth: advanceWidth,
+		Unknown1:     uint8(offsetY),
+		Unknown2:     uint8(uint16(offsetY) >> 8),
+	}, nil
+}
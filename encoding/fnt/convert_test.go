@@ -0,0 +1,148 @@
+package fnt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// testBDF is a BDF font with a 4x5 "A", a space and a "g" that descends below
+// the baseline.
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--8-80-75-75-c-60-iso10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 4 7 0 -2
+STARTPROPERTIES 2
+FONT_ASCENT 6
+FONT_DESCENT 2
+ENDPROPERTIES
+CHARS 3
+STARTCHAR space
+ENCODING 32
+SWIDTH 750 0
+DWIDTH 6 0
+BBX 0 0 0 0
+BITMAP
+ENDCHAR
+STARTCHAR A
+ENCODING 65
+SWIDTH 750 0
+DWIDTH 5 0
+BBX 4 5 0 0
+BITMAP
+60
+90
+F0
+90
+90
+ENDCHAR
+STARTCHAR g
+ENCODING 103
+SWIDTH 750 0
+DWIDTH 5 0
+BBX 3 5 1 -2
+BITMAP
+E0
+A0
+E0
+20
+E0
+ENDCHAR
+ENDFONT
+`
+
+func TestFromBDF(t *testing.T) {
+	f, err := FromBDF(strings.NewReader(testBDF), nil)
+	if err != nil {
+		t.Fatalf("FromBDF() error = %v, want nil", err)
+	}
+
+	if got, want := f.LineHeight, uint32(8); got != want {
+		t.Errorf("Font.LineHeight = %d, want %d", got, want)
+	}
+
+	space := f.Glyphs[' ']
+	if space.Type != GlyphTypeEmpty || space.AdvanceWidth != 6 {
+		t.Errorf("space glyph = %+v, want empty with advance width 6", space)
+	}
+
+	a := f.Glyphs['A']
+	if got, want := a.OffsetY(), -5; got != want {
+		t.Errorf("A glyph OffsetY() = %d, want %d", got, want)
+	}
+	wantA := []uint8{
+		0, 15, 15, 0,
+		15, 0, 0, 15,
+		15, 15, 15, 15,
+		15, 0, 0, 15,
+		15, 0, 0, 15,
+	}
+	if diff := cmp.Diff(wantA, a.Image.Pix); diff != "" {
+		t.Errorf("A glyph pixels mismatch (-want +got):\n%s", diff)
+	}
+
+	// The "g" is offset 1 pixel to the right and its width is padded to an
+	// even number of pixels.
+	g := f.Glyphs['g']
+	if got, want := g.Image.Rect.Dx(), 4; got != want {
+		t.Errorf("g glyph width = %d, want %d", got, want)
+	}
+	if got, want := g.OffsetY(), -3; got != want {
+		t.Errorf("g glyph OffsetY() = %d, want %d", got, want)
+	}
+	if got := g.Image.ColorIndexAt(0, 0); got != 0 {
+		t.Errorf("g glyph index at (0,0) = %d, want 0", got)
+	}
+
+	if f.Glyphs['B'].Type != GlyphTypeEmpty {
+		t.Errorf("B glyph type = %v, want empty", f.Glyphs['B'].Type)
+	}
+}
+
+func TestFromTrueType(t *testing.T) {
+	f, err := FromTrueType(goregular.TTF, 12, nil)
+	if err != nil {
+		t.Fatalf("FromTrueType() error = %v, want nil", err)
+	}
+
+	for _, r := range "AZaz09éŒ" {
+		b, _ := CodePage().EncodeRune(r)
+		g := f.Glyphs[b]
+		if g.Type != GlyphTypeNormal {
+			t.Errorf("glyph %q type = %v, want normal", r, g.Type)
+			continue
+		}
+		if g.OffsetY() >= 0 {
+			t.Errorf("glyph %q OffsetY() = %d, want above the baseline", r, g.OffsetY())
+		}
+	}
+
+	// The converted font can be encoded and decoded again.
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).Encode(f); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+	decoded, err := NewDecoder(bytes.NewReader(buf.Bytes())).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	for i, want := range f.Glyphs {
+		got := decoded.Glyphs[i]
+		if got.Type != want.Type || got.AdvanceWidth != want.AdvanceWidth || got.OffsetY() != want.OffsetY() {
+			t.Fatalf("decoded glyph %d = %+v, want %+v", i, got, want)
+		}
+		if want.Type == GlyphTypeNormal && !bytes.Equal(got.Image.Pix, want.Image.Pix) {
+			t.Fatalf("decoded glyph %d pixels differ", i)
+		}
+	}
+}
+
+func TestFromFace_palette(t *testing.T) {
+	_, err := FromBDF(strings.NewReader(testBDF), &ConvertOptions{Palette: DefaultPalette()[:2]})
+	if want := "palette has 2 color(s), expected 16"; err == nil || err.Error() != want {
+		t.Errorf("FromBDF() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,161 @@
+package fnt
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// Align specifies the horizontal alignment of each line of text.
+type Align uint8
+
+const (
+	// AlignLeft aligns lines to the left edge of the text.
+	AlignLeft Align = iota
+	// AlignCenter centers lines within the text.
+	AlignCenter
+	// AlignRight aligns lines to the right edge of the text.
+	AlignRight
+)
+
+// DrawOptions configures how text is laid out and drawn.
+type DrawOptions struct {
+	// Src is the image glyphs are colored with. If nil, each glyph is drawn
+	// in the colors of Palette.
+	Src image.Image
+	// Palette is the color table glyphs are drawn with when Src is nil, e.g.
+	// Font.ColorTable2. If nil, Font.ColorTable1 is used.
+	Palette color.Palette
+	// Width is the width, in pixels, at which lines are word wrapped. If 0,
+	// lines are only broken at newlines.
+	Width int
+	// Align is the horizontal alignment of each line. Lines are aligned within
+	// Width, or within the widest line if Width is 0.
+	Align Align
+}
+
+// line is a single line of laid out text.
+type line struct {
+	text  string
+	width int
+}
+
+// Measure returns the size, in pixels, of text when laid out with face and
+// opts. opts may be nil.
+func Measure(face *Face, text string, opts *DrawOptions) image.Point {
+	if opts == nil {
+		opts = &DrawOptions{}
+	}
+	lines := layout(face, text, opts.Width)
+	return image.Pt(textWidth(lines, opts.Width), len(lines)*int(face.font.LineHeight))
+}
+
+// DrawString draws text with face onto dst. pt is the top-left corner of the
+// first line. Each line is the font's LineHeight pixels high and glyphs are
+// placed relative to a baseline that is the font's ascent below the top of the
+// line. opts may be nil.
+//
+// The face's glyph masks are built once by NewFace, so a face should be reused
+// to draw more than one string with the same font.
+func DrawString(dst draw.Image, pt image.Point, face *Face, text string, opts *DrawOptions) {
+	if opts == nil {
+		opts = &DrawOptions{}
+	}
+	lines := layout(face, text, opts.Width)
+	width := textWidth(lines, opts.Width)
+
+	for i, l := range lines {
+		x := pt.X
+		switch opts.Align {
+		case AlignCenter:
+			x += (width - l.width) / 2
+		case AlignRight:
+			x += width - l.width
+		}
+		y := pt.Y + i*int(face.font.LineHeight) + face.ascent
+
+		dot := fixed.P(x, y)
+		for _, r := range l.text {
+			gi, g, ok := face.glyph(r)
+			if !ok {
+				continue
+			}
+			dr, mask, maskp, advance, _ := face.Glyph(dot, r)
+			dot.X += advance
+			if face.masks[gi] == nil {
+				continue
+			}
+
+			src, sp := opts.Src, dr.Min
+			if src == nil {
+				img := g.Image
+				if opts.Palette != nil {
+					img = g.Render(opts.Palette)
+				}
+				src, sp = img, img.Bounds().Min
+			}
+			draw.DrawMask(dst, dr, src, sp, mask, maskp, draw.Over)
+		}
+	}
+}
+
+// textWidth returns the width of the laid out lines, which is width if it is
+// set and the width of the widest line otherwise.
+func textWidth(lines []line, width int) int {
+	if width > 0 {
+		return width
+	}
+	for _, l := range lines {
+		width = max(width, l.width)
+	}
+	return width
+}
+
+// layout breaks text into lines at newlines, either "\n" or "\r\n", and, if
+// width is greater than 0, wraps words so that lines are no wider than width. A
+// word that is wider than width on its own is placed on a line by itself.
+func layout(face *Face, text string, width int) []line {
+	var lines []line
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if width <= 0 {
+			lines = append(lines, line{text: paragraph, width: measureLine(face, paragraph)})
+			continue
+		}
+
+		var current line
+		for i, word := range strings.Split(paragraph, " ") {
+			if i == 0 {
+				current = line{text: word, width: measureLine(face, word)}
+				continue
+			}
+			candidate := current.text + " " + word
+			if w := measureLine(face, candidate); w <= width {
+				current = line{text: candidate, width: w}
+				continue
+			}
+			lines = append(lines, current)
+			current = line{text: word, width: measureLine(face, word)}
+		}
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+// measureLine returns the width of s, in pixels, which is the sum of the
+// advances of its glyphs.
+func measureLine(face *Face, s string) int {
+	var width int
+	for _, r := range s {
+		_, g, ok := face.glyph(r)
+		if !ok {
+			continue
+		}
+		width += face.advance(g)
+	}
+	return width
+}
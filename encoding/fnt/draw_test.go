@@ -0,0 +1,134 @@
+package fnt
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMeasure(t *testing.T) {
+	face := NewFace(decodeTestFont(t, "F_HELP.FNT"))
+
+	tests := []struct {
+		name string
+		text string
+		opts *DrawOptions
+		want image.Point
+	}{
+		{
+			name: "empty",
+			text: "",
+			want: image.Pt(0, 9),
+		},
+		{
+			name: "single line",
+			text: "AB",
+			want: image.Pt(8, 9),
+		},
+		{
+			name: "newlines",
+			text: "AB\nA",
+			want: image.Pt(8, 18),
+		},
+		{
+			name: "CRLF newlines",
+			text: "AB\r\nA\r\n",
+			want: image.Pt(8, 27),
+		},
+		{
+			name: "word wrap",
+			text: "A A A",
+			opts: &DrawOptions{Width: 12},
+			want: image.Pt(12, 18),
+		},
+		{
+			name: "word wider than width",
+			text: "AAAA A",
+			opts: &DrawOptions{Width: 8},
+			want: image.Pt(8, 18),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Measure(face, tt.text, tt.opts); got != tt.want {
+				t.Errorf("Measure(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayout_crlf(t *testing.T) {
+	face := NewFace(decodeTestFont(t, "F_HELP.FNT"))
+
+	var got []string
+	for _, l := range layout(face, "AB\r\nA B\r\n", 0) {
+		got = append(got, l.text)
+	}
+	if diff := cmp.Diff([]string{"AB", "A B", ""}, got); diff != "" {
+		t.Errorf("layout() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDrawString(t *testing.T) {
+	face := NewFace(decodeTestFont(t, "F_HELP.FNT"))
+
+	// drawnBounds returns the bounds of the pixels that were drawn onto a
+	// transparent image.
+	drawnBounds := func(img *image.NRGBA) image.Rectangle {
+		var r image.Rectangle
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				if img.NRGBAAt(x, y).A != 0 {
+					r = r.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		return r
+	}
+
+	t.Run("glyph colors", func(t *testing.T) {
+		dst := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+		DrawString(dst, image.Pt(2, 1), face, "A", nil)
+
+		// The font's ascent is 7 so the baseline is at y=8 and the 5 pixel
+		// high "A" starts at y=3.
+		if got, want := drawnBounds(dst), image.Rect(2, 3, 6, 8); !got.In(want) || got.Empty() {
+			t.Errorf("DrawString() drew within %v, want within %v", got, want)
+		}
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				if c := dst.NRGBAAt(x, y); c.A != 0 && c == (color.NRGBA{R: 0xff, B: 0xff, A: 0xff}) {
+					t.Fatalf("DrawString() drew transparent color at (%d,%d)", x, y)
+				}
+			}
+		}
+	})
+
+	t.Run("source color and alignment", func(t *testing.T) {
+		dst := image.NewNRGBA(image.Rect(0, 0, 16, 32))
+		red := color.NRGBA{R: 0xff, A: 0xff}
+		DrawString(dst, image.Pt(0, 0), face, "A\nAA", &DrawOptions{
+			Src:   image.NewUniform(red),
+			Align: AlignRight,
+		})
+
+		firstLine := dst.SubImage(image.Rect(0, 0, 16, 9)).(*image.NRGBA)
+		if got := drawnBounds(firstLine); got.Min.X < 4 {
+			t.Errorf("DrawString() first line starts at x=%d, want right aligned at x>=4", got.Min.X)
+		}
+		secondLine := dst.SubImage(image.Rect(0, 9, 16, 18)).(*image.NRGBA)
+		if got := drawnBounds(secondLine); got.Min.X != 0 {
+			t.Errorf("DrawString() second line starts at x=%d, want 0", got.Min.X)
+		}
+
+		for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+			for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x++ {
+				if c := dst.NRGBAAt(x, y); c.A != 0 && c != red {
+					t.Fatalf("DrawString() drew %v at (%d,%d), want %v", c, x, y, red)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package fnt
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// CodePage returns the code page that maps runes to the 256 glyph slots of a
+// font. Dark Omen's fonts are laid out in the Windows-1252 code page, e.g.
+// glyph 0xE9 is "é" and glyph 0x8C is "Œ".
+func CodePage() *charmap.Charmap {
+	return charmap.Windows1252
+}
+
+// Face implements the golang.org/x/image/font.Face interface for a Font so
+// that text can be drawn with a font.Drawer.
+//
+// Each glyph is used as an alpha mask: pixels drawn with color index 0 are
+// masked out and all other pixels are opaque, so text is drawn in
+// the color of the font.Drawer's Src.
+//
+// It is safe to use concurrently.
+type Face struct {
+	font    *Font
+	masks   []*image.Alpha
+	ascent  int
+	descent int
+}
+
+var _ font.Face = (*Face)(nil)
+
+// NewFace returns a new font.Face for f.
+func NewFace(f *Font) *Face {
+	face := &Face{
+		font:  f,
+		masks: make([]*image.Alpha, len(f.Glyphs)),
+	}
+
+	for i, g := range f.Glyphs {
+		if g.Type == GlyphTypeEmpty || g.Image == nil {
+			continue
+		}
+		face.masks[i] = alphaMask(g.Image)
+
+		top := g.OffsetY()
+		bottom := top + g.Image.Bounds().Dy()
+		face.ascent = max(face.ascent, -top)
+		face.descent = max(face.descent, bottom)
+	}
+
+	return face
+}
+
+// alphaMask returns an alpha mask of img that is transparent wherever img uses
+// color index 0 and opaque elsewhere.
+func alphaMask(img *image.Paletted) *image.Alpha {
+	bounds := img.Bounds()
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if img.ColorIndexAt(x, y) == 0 {
+				continue
+			}
+			mask.SetAlpha(x-bounds.Min.X, y-bounds.Min.Y, color.Alpha{A: 0xff})
+		}
+	}
+
+	return mask
+}
+
+// glyph returns the index and glyph for r.
+func (f *Face) glyph(r rune) (int, *Glyph, bool) {
+	b, ok := CodePage().EncodeRune(r)
+	if !ok || int(b) >= len(f.font.Glyphs) {
+		return 0, nil, false
+	}
+	g := f.font.Glyphs[b]
+	if g == nil || g.Type == GlyphTypeEmpty && g.AdvanceWidth == 0 {
+		return 0, nil, false
+	}
+	return int(b), g, true
+}
+
+// advance returns the advance of g, in pixels.
+func (f *Face) advance(g *Glyph) int {
+	return int(g.AdvanceWidth) + int(f.font.BaseAdvanceWidth)
+}
+
+// Close implements font.Face. It does nothing and always returns nil.
+func (f *Face) Close() error { return nil }
+
+// Glyph implements font.Face.
+func (f *Face) Glyph(dot fixed.Point26_6, r rune) (
+	dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+
+	i, g, ok := f.glyph(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+
+	advance = fixed.I(f.advance(g))
+
+	m := f.masks[i]
+	if m == nil {
+		return image.Rectangle{}, image.NewAlpha(image.Rectangle{}), image.Point{}, advance, true
+	}
+
+	x := dot.X.Round()
+	y := dot.Y.Round() + g.OffsetY()
+	dr = m.Rect.Add(image.Pt(x, y))
+
+	return dr, m, image.Point{}, advance, true
+}
+
+// GlyphBounds implements font.Face.
+func (f *Face) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	_, g, ok := f.glyph(r)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+
+	advance = fixed.I(f.advance(g))
+
+	if g.Type == GlyphTypeEmpty || g.Image == nil {
+		return fixed.Rectangle26_6{}, advance, true
+	}
+
+	size := g.Image.Bounds().Size()
+	top := g.OffsetY()
+
+	return fixed.R(0, top, size.X, top+size.Y), advance, true
+}
+
+// GlyphAdvance implements font.Face.
+func (f *Face) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	_, g, ok := f.glyph(r)
+	if !ok {
+		return 0, false
+	}
+	return fixed.I(f.advance(g)), true
+}
+
+// Kern implements font.Face. Dark Omen fonts have no kerning so it always
+// returns 0.
+func (f *Face) Kern(r0, r1 rune) fixed.Int26_6 { return 0 }
+
+// Metrics implements font.Face.
+func (f *Face) Metrics() font.Metrics {
+	m := font.Metrics{
+		Height:     fixed.I(int(f.font.LineHeight)),
+		Ascent:     fixed.I(f.ascent),
+		Descent:    fixed.I(f.descent),
+		CaretSlope: image.Point{X: 0, Y: 1},
+	}
+	if b, _, ok := f.GlyphBounds('x'); ok {
+		m.XHeight = -b.Min.Y
+	}
+	if b, _, ok := f.GlyphBounds('H'); ok {
+		m.CapHeight = -b.Min.Y
+	}
+	return m
+}
@@ -0,0 +1,127 @@
+package fnt
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func decodeTestFont(t *testing.T, name string) *Font {
+	t.Helper()
+
+	f, err := os.Open(path.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	fnt, err := NewDecoder(f).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	return fnt
+}
+
+func TestFace_GlyphBounds(t *testing.T) {
+	face := NewFace(decodeTestFont(t, "F_HELP.FNT"))
+
+	tests := []struct {
+		name        string
+		r           rune
+		wantBounds  fixed.Rectangle26_6
+		wantAdvance fixed.Int26_6
+		wantOK      bool
+	}{
+		{
+			name:        "capital",
+			r:           'A',
+			wantBounds:  fixed.R(0, -5, 4, 0),
+			wantAdvance: fixed.I(4),
+			wantOK:      true,
+		},
+		{
+			name:        "descender",
+			r:           'g',
+			wantBounds:  fixed.R(0, -3, 4, 2),
+			wantAdvance: fixed.I(4),
+			wantOK:      true,
+		},
+		{
+			name:        "code page",
+			r:           'é',
+			wantBounds:  fixed.R(0, -6, 4, 0),
+			wantAdvance: fixed.I(4),
+			wantOK:      true,
+		},
+		{
+			name:        "space",
+			r:           ' ',
+			wantAdvance: fixed.I(4),
+			wantOK:      true,
+		},
+		{
+			name: "missing glyph",
+			r:    '#',
+		},
+		{
+			name: "outside code page",
+			r:    '世',
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBounds, gotAdvance, gotOK := face.GlyphBounds(tt.r)
+			if gotBounds != tt.wantBounds || gotAdvance != tt.wantAdvance || gotOK != tt.wantOK {
+				t.Errorf("GlyphBounds(%q) = %v, %v, %v, want %v, %v, %v", tt.r, gotBounds, gotAdvance, gotOK, tt.wantBounds, tt.wantAdvance, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFace_Draw(t *testing.T) {
+	face := NewFace(decodeTestFont(t, "F_HELP.FNT"))
+
+	m := face.Metrics()
+	if got, want := m.Height, fixed.I(9); got != want {
+		t.Errorf("Metrics().Height = %v, want %v", got, want)
+	}
+	if got, want := m.Ascent, fixed.I(7); got != want {
+		t.Errorf("Metrics().Ascent = %v, want %v", got, want)
+	}
+	if got, want := m.Descent, fixed.I(2); got != want {
+		t.Errorf("Metrics().Descent = %v, want %v", got, want)
+	}
+
+	dst := image.NewAlpha(image.Rect(0, 0, 32, 16))
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(color.Alpha{A: 0xff}),
+		Face: face,
+		Dot:  fixed.P(0, 10),
+	}
+	d.DrawString("Ag")
+
+	if got, want := d.Dot.X, fixed.I(8); got != want {
+		t.Errorf("Drawer.Dot.X = %v, want %v", got, want)
+	}
+
+	drawn := image.Rectangle{}
+	for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+		for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x++ {
+			if dst.AlphaAt(x, y).A != 0 {
+				drawn = drawn.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if drawn.Empty() {
+		t.Fatal("DrawString() drew nothing")
+	}
+	if want := image.Rect(0, 5, 8, 12); !drawn.In(want) {
+		t.Errorf("DrawString() drew within %v, want within %v", drawn, want)
+	}
+}
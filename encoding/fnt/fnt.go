@@ -20,9 +20,14 @@ const (
 )
 
 type Font struct {
-	format      string
-	colorTable1 []color.RGBA
-	colorTable2 []color.RGBA
+	format string
+
+	// ColorTable1 is the 16 color palette that glyphs are drawn with by
+	// default. Index 0 is the transparent background color.
+	ColorTable1 color.Palette
+	// ColorTable2 is a second 16 color palette stored in the font. Its use is
+	// unknown.
+	ColorTable2 color.Palette
 
 	BaseAdvanceWidth uint16
 	// LineHeight is the total height of each line in the font. This can be used
@@ -56,8 +61,9 @@ const (
 type Glyph struct {
 	// Type provides information about how to interpret the glyph image.
 	Type GlyphType
-	// Image is the decoded glyph data converted into an image.Image.
-	Image image.Image
+	// Image is the decoded glyph data. Each pixel is a 4-bit index into the
+	// font's color table and the image's palette is Font.ColorTable1.
+	Image *image.Paletted
 	// The AdvanceWidth of the glyph. After the glyph is rendered, the start of
 	// the next glyph is offset on the x-axis from the current glyph origin by
 	// this amount, plus the base advance width found in Font.BaseAdvanceWidth.
@@ -66,11 +72,33 @@ type Glyph struct {
 	// glyph.
 	AdvanceWidth uint16
 
-	// TODO: Both of these affect vertical/y-axis positioning when rendering.
+	// Unknown1 and Unknown2 affect vertical/y-axis positioning when rendering.
+	// Together they appear to be the low and high bytes of a signed 16-bit
+	// offset, which is returned by OffsetY.
 	Unknown1 uint8
 	Unknown2 uint8
 }
 
+// OffsetY returns the offset on the y-axis from the baseline to the top of the
+// glyph image. It is negative for glyphs that sit above the baseline. For
+// example, in F_HELP.FNT the 5 pixel high "A" has an offset of -5 and the "g"
+// has an offset of -3 so that its tail descends 2 pixels below the baseline.
+func (g *Glyph) OffsetY() int {
+	return int(int16(uint16(g.Unknown1) | uint16(g.Unknown2)<<8))
+}
+
+// Render returns the glyph image drawn with palette instead of the font's
+// default color table, e.g. Render(f.ColorTable2). The returned image shares
+// its pixels with g.Image. It returns nil for empty glyphs.
+func (g *Glyph) Render(palette color.Palette) *image.Paletted {
+	if g.Image == nil {
+		return nil
+	}
+	img := *g.Image
+	img.Palette = palette
+	return &img
+}
+
 // Decoder reads and decodes a font from an input stream.
 type Decoder struct {
 	r io.ReaderAt
@@ -108,6 +136,8 @@ func (d *Decoder) Decode() (*Font, error) {
 
 	f := &Font{
 		format:            format,
+		ColorTable1:       colorTable1,
+		ColorTable2:       colorTable2,
 		BaseAdvanceWidth:  header.baseAdvanceWidth,
 		LineHeight:        header.lineHeight,
 		BaseAdvanceHeight: header.baseAdvanceHeight,
@@ -121,7 +151,7 @@ func (d *Decoder) Decode() (*Font, error) {
 		return f, nil
 	}
 
-	glyphs, err := d.readGlyphData(header, glyphHeaders, colorTable1, colorTable2)
+	glyphs, err := d.readGlyphData(header, glyphHeaders, colorTable1)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +200,7 @@ func (d *Decoder) readHeader() (h *header, pos int64, err error) {
 	return h, pos, nil
 }
 
-func (d *Decoder) readColorTable(startPos int64) (colors []color.RGBA, pos int64, err error) {
+func (d *Decoder) readColorTable(startPos int64) (colors color.Palette, pos int64, err error) {
 	pos = startPos
 	table := make([]byte, colorTableSize)
 	n, err := d.r.ReadAt(table, pos)
@@ -182,7 +212,7 @@ func (d *Decoder) readColorTable(startPos int64) (colors []color.RGBA, pos int64
 		return nil, pos, err
 	}
 
-	colors = make([]color.RGBA, colorTableColorCount)
+	colors = make(color.Palette, colorTableColorCount)
 
 	for i := uint16(0); i < colorTableColorCount; i++ {
 		entry := table[4*i : 4*(i+1)]
@@ -257,7 +287,7 @@ func (d *Decoder) readGlyphHeaders(header *header, startPos int64) ([]*glyphHead
 	return headers, nil
 }
 
-func (d *Decoder) readGlyphData(header *header, glyphHeaders []*glyphHeader, colors1, colors2 []color.RGBA) ([]*Glyph, error) {
+func (d *Decoder) readGlyphData(header *header, glyphHeaders []*glyphHeader, palette color.Palette) ([]*Glyph, error) {
 	glyphs := make([]*Glyph, len(glyphHeaders))
 
 	for i, info := range glyphHeaders {
@@ -286,7 +316,7 @@ func (d *Decoder) readGlyphData(header *header, glyphHeaders []*glyphHeader, col
 			return nil, err
 		}
 
-		img := image.NewNRGBA(image.Rect(0, 0, int(info.width), int(info.height)))
+		img := image.NewPaletted(image.Rect(0, 0, int(info.width), int(info.height)), palette)
 
 		var x int
 		var y int
@@ -295,9 +325,9 @@ func (d *Decoder) readGlyphData(header *header, glyphHeaders []*glyphHeader, col
 			lo := b & 0x0F
 			hi := b >> 4
 
-			img.Set(x, y, colors1[lo])
+			img.SetColorIndex(x, y, lo)
 			x, y = xy(img, x, y)
-			img.Set(x, y, colors1[hi])
+			img.SetColorIndex(x, y, hi)
 			x, y = xy(img, x, y)
 		}
 
@@ -311,9 +341,161 @@ func (d *Decoder) readGlyphData(header *header, glyphHeaders []*glyphHeader, col
 
 // xy returns the next x, y coordinates for the given image keeping within its
 // dimensions.
-func xy(img *image.NRGBA, x, y int) (int, int) {
-	if x == img.Rect.Max.X-1 {
+func xy(img image.Image, x, y int) (int, int) {
+	if x == img.Bounds().Max.X-1 {
 		return 0, y + 1
 	}
 	return x + 1, y
 }
+
+const (
+	// glyphDataStart is the offset of the glyph data in every encoded font.
+	glyphDataStart = headerSize + 2*colorTableSize + glyphCount*glyphHeaderSize
+
+	// glyphOwnData and glyphSharedData are stored in the first byte of a
+	// glyph header. Glyphs with identical images share the same glyph data,
+	// e.g. "0" and "O" in F_HELP.FNT.
+	glyphOwnData    = 1
+	glyphSharedData = 2
+)
+
+// emptyGlyphDataOffset is stored in place of the data offset of empty glyphs.
+// It is the fill pattern the Microsoft C runtime uses for uninitialized heap
+// memory and is found in every font shipped with the game.
+var emptyGlyphDataOffset = []byte{0xCD, 0xCD, 0xCD, 0xCD}
+
+// Encoder encodes and writes a font to an output stream.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the encoded font to its output.
+//
+// The header is written from f.BaseAdvanceWidth, f.BaseAdvanceHeight,
+// f.Height2 and f.Unknown1. f.LineHeight is not written as it is derived from
+// f.BaseAdvanceHeight and f.Height2. If f.ColorTable2 is nil, f.ColorTable1 is
+// written in its place. Glyphs with identical images share their glyph data.
+func (e *Encoder) Encode(f *Font) error {
+	if n := len(f.Glyphs); n != glyphCount {
+		return fmt.Errorf("font has %d glyph(s), expected %d", n, glyphCount)
+	}
+
+	buf := make([]byte, glyphDataStart)
+
+	copy(buf[0:4], format)
+	binary.LittleEndian.PutUint16(buf[4:6], f.BaseAdvanceWidth)
+	binary.LittleEndian.PutUint16(buf[6:8], f.BaseAdvanceHeight)
+	binary.LittleEndian.PutUint16(buf[8:10], f.Height2)
+	binary.LittleEndian.PutUint16(buf[10:12], f.Unknown1)
+	binary.LittleEndian.PutUint16(buf[12:14], glyphDataStart)
+
+	colorTable2 := f.ColorTable2
+	if colorTable2 == nil {
+		colorTable2 = f.ColorTable1
+	}
+	if err := putColorTable(buf[headerSize:], f.ColorTable1); err != nil {
+		return fmt.Errorf("could not write color table 1: %w", err)
+	}
+	if err := putColorTable(buf[headerSize+colorTableSize:], colorTable2); err != nil {
+		return fmt.Errorf("could not write color table 2: %w", err)
+	}
+
+	var data []byte
+	offsets := make(map[string]int)
+
+	for i, g := range f.Glyphs {
+		h := buf[headerSize+2*colorTableSize+i*glyphHeaderSize:][:glyphHeaderSize]
+
+		if g == nil {
+			copy(h[12:16], emptyGlyphDataOffset)
+			continue
+		}
+
+		h[2] = g.Unknown1
+		h[3] = g.Unknown2
+		binary.LittleEndian.PutUint16(h[6:8], g.AdvanceWidth)
+
+		if g.Type == GlyphTypeEmpty || g.Image == nil || g.Image.Rect.Empty() {
+			copy(h[12:16], emptyGlyphDataOffset)
+			continue
+		}
+
+		packed, err := packGlyph(g.Image)
+		if err != nil {
+			return fmt.Errorf("could not write glyph %d: %w", i, err)
+		}
+
+		offset, ok := offsets[string(packed)]
+		if ok {
+			h[0] = glyphSharedData
+		} else {
+			h[0] = glyphOwnData
+			offset = len(data)
+			offsets[string(packed)] = offset
+			data = append(data, packed...)
+		}
+		if offset+glyphDataStart > 0xFFFF {
+			return fmt.Errorf("glyph %d data offset %d is too large, the glyph data must be less than %d bytes", i, offset, 0xFFFF-glyphDataStart)
+		}
+
+		size := g.Image.Rect.Size()
+		binary.LittleEndian.PutUint16(h[4:6], uint16(size.X))
+		binary.LittleEndian.PutUint16(h[8:10], uint16(size.Y))
+		binary.LittleEndian.PutUint32(h[12:16], uint32(offset))
+	}
+
+	if _, err := e.w.Write(buf); err != nil {
+		return err
+	}
+	_, err := e.w.Write(data)
+	return err
+}
+
+func putColorTable(buf []byte, colors color.Palette) error {
+	if n := len(colors); n != colorTableColorCount {
+		return fmt.Errorf("color table has %d color(s), expected %d", n, colorTableColorCount)
+	}
+
+	for i, c := range colors {
+		nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+		entry := buf[4*i : 4*(i+1)]
+		entry[0] = nrgba.B
+		entry[1] = nrgba.G
+		entry[2] = nrgba.R
+		// byte 4 (index 3) is not used
+	}
+
+	return nil
+}
+
+// packGlyph returns the glyph image packed into 4-bit color indices, two per
+// byte with the first pixel in the low nibble.
+func packGlyph(img *image.Paletted) ([]byte, error) {
+	size := img.Rect.Size()
+	if size.X%2 != 0 {
+		return nil, fmt.Errorf("glyph width %d is odd, expected an even width", size.X)
+	}
+	if size.X > 0xFFFF || size.Y > 0xFFFF {
+		return nil, fmt.Errorf("glyph size %v is too large", size)
+	}
+
+	packed := make([]byte, 0, size.X*size.Y/2)
+
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x += 2 {
+			lo := img.ColorIndexAt(x, y)
+			hi := img.ColorIndexAt(x+1, y)
+			if lo >= colorTableColorCount || hi >= colorTableColorCount {
+				return nil, fmt.Errorf("color index at (%d,%d) is out of range, expected less than %d", x, y, colorTableColorCount)
+			}
+			packed = append(packed, lo|hi<<4)
+		}
+	}
+
+	return packed, nil
+}
@@ -3,12 +3,14 @@ package fnt
 import (
 	"bytes"
 	"image"
+	"image/color"
+	"io"
 	"os"
 	"path"
 	"testing"
 )
 
-var font *Font
+var decodedFont *Font
 
 func BenchmarkDecode(b *testing.B) {
 	tests := []string{
@@ -35,11 +37,89 @@ func BenchmarkDecode(b *testing.B) {
 					b.Fatalf("Decode() error = %v, want nil", err)
 				}
 			}
-			font = f
+			decodedFont = f
 		})
 	}
 }
 
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		"F_MENBG.FNT",
+		"F_HELP.FNT",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			want, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := NewDecoder(bytes.NewReader(want)).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			got := &bytes.Buffer{}
+			if err := NewEncoder(got).Encode(f); err != nil {
+				t.Fatalf("Encode() error = %v, want nil", err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("got encoded bytes = %v [output truncated], want %v [output truncated]", truncateBytes(got.Bytes(), 10), truncateBytes(want, 10))
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	f := decodeTestFont(t, "F_HELP.FNT")
+
+	tests := []struct {
+		name    string
+		font    func() *Font
+		wantErr string
+	}{
+		{
+			name: "missing glyphs",
+			font: func() *Font {
+				return &Font{ColorTable1: f.ColorTable1, Glyphs: f.Glyphs[:10]}
+			},
+			wantErr: "font has 10 glyph(s), expected 256",
+		},
+		{
+			name: "short color table",
+			font: func() *Font {
+				return &Font{ColorTable1: f.ColorTable1[:4], Glyphs: f.Glyphs}
+			},
+			wantErr: "could not write color table 1: color table has 4 color(s), expected 16",
+		},
+		{
+			name: "odd glyph width",
+			font: func() *Font {
+				glyphs := make([]*Glyph, len(f.Glyphs))
+				copy(glyphs, f.Glyphs)
+				glyphs['A'] = &Glyph{Image: image.NewPaletted(image.Rect(0, 0, 3, 2), f.ColorTable1)}
+				return &Font{ColorTable1: f.ColorTable1, Glyphs: glyphs}
+			},
+			wantErr: "could not write glyph 65: glyph width 3 is odd, expected an even width",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewEncoder(io.Discard).Encode(tt.font())
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func truncateBytes(bs []byte, size int) []byte {
+	if len(bs) > size {
+		return bs[:size]
+	}
+	return bs
+}
+
 func Test_xy(t *testing.T) {
 	type args struct {
 		img *image.NRGBA
@@ -80,3 +160,44 @@ func Test_xy(t *testing.T) {
 		})
 	}
 }
+
+func TestGlyph_Render(t *testing.T) {
+	f := decodeTestFont(t, "F_HELP.FNT")
+
+	if got, want := len(f.ColorTable1), colorTableColorCount; got != want {
+		t.Fatalf("len(Font.ColorTable1) = %d, want %d", got, want)
+	}
+	if got, want := len(f.ColorTable2), colorTableColorCount; got != want {
+		t.Fatalf("len(Font.ColorTable2) = %d, want %d", got, want)
+	}
+
+	g := f.Glyphs['A']
+	if got, want := g.Image.Palette, f.ColorTable1; &got[0] != &want[0] {
+		t.Errorf("Glyph.Image.Palette is not Font.ColorTable1")
+	}
+
+	palette := make(color.Palette, colorTableColorCount)
+	for i := range palette {
+		palette[i] = color.Gray{Y: uint8(i * 16)}
+	}
+
+	img := g.Render(palette)
+	if got, want := img.Bounds(), g.Image.Bounds(); got != want {
+		t.Fatalf("Glyph.Render() bounds = %v, want %v", got, want)
+	}
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			i := g.Image.ColorIndexAt(x, y)
+			if got := img.ColorIndexAt(x, y); got != i {
+				t.Fatalf("Glyph.Render() index at (%d,%d) = %d, want %d", x, y, got, i)
+			}
+			if got, want := img.At(x, y), palette[i]; got != want {
+				t.Fatalf("Glyph.Render() color at (%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	if got := f.Glyphs['#'].Render(palette); got != nil {
+		t.Errorf("Glyph.Render() of empty glyph = %v, want nil", got)
+	}
+}
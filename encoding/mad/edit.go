@@ -0,0 +1,61 @@
+package mad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+)
+
+// The functions and methods in this file decode the streams they are given
+// and return new streams that encode the result with FromPCM, so the block
+// layout and sentinel block of the result are always valid. Re-encoding the
+// audio as IMA ADPCM adds a little noise.
+
+// Trim returns a new stream holding the stream's audio from start to end.
+// Times are rounded to the nearest sample.
+func (s *Stream) Trim(start, end time.Duration) (*Stream, error) {
+	samples := adpcm.AppendSamples(nil, s.Blocks)
+	i, j := internalaudio.NumSamples(sampleRate, start), internalaudio.NumSamples(sampleRate, end)
+	if i < 0 || j < i || j > len(samples) {
+		return nil, fmt.Errorf("invalid range %v to %v of a %v stream", start, end, s.Duration())
+	}
+	return FromPCM(samples[i:j]), nil
+}
+
+// Concat returns a new stream holding the audio of each stream in turn.
+func Concat(streams ...*Stream) *Stream {
+	var samples []int16
+	for _, s := range streams {
+		samples = adpcm.AppendSamples(samples, s.Blocks)
+	}
+	return FromPCM(samples)
+}
+
+// Mix returns a new stream holding the audio of a with that of b, scaled by
+// gain, added to it. The stream is as long as the longer of a and b. Samples
+// that would exceed 16 bits are clipped.
+func Mix(a, b *Stream, gain float64) *Stream {
+	return FromPCM(internalaudio.Mix(
+		adpcm.AppendSamples(nil, a.Blocks),
+		adpcm.AppendSamples(nil, b.Blocks),
+		gain,
+	))
+}
+
+// FadeIn returns a new stream holding the stream's audio with a linear fade
+// from silence over its first d. The whole stream is faded if it is shorter
+// than d, and none of it is faded if d is not positive.
+func (s *Stream) FadeIn(d time.Duration) *Stream {
+	samples := adpcm.AppendSamples(nil, s.Blocks)
+	return FromPCM(internalaudio.FadeIn(samples, internalaudio.NumSamples(sampleRate, d)))
+}
+
+// FadeOut returns a new stream holding the stream's audio with a linear fade
+// to silence over its last d. The whole stream is faded if it is shorter than
+// d, and none of it is faded if d is not positive.
+func (s *Stream) FadeOut(d time.Duration) *Stream {
+	samples := adpcm.AppendSamples(nil, s.Blocks)
+	return FromPCM(internalaudio.FadeOut(samples, internalaudio.NumSamples(sampleRate, d)))
+}
@@ -0,0 +1,128 @@
+package mad
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+)
+
+func TestEdit(t *testing.T) {
+	a := FromPCM(audiotest.Tone(sampleRate, sampleRate*2, 440, 8000))
+	b := FromPCM(audiotest.Tone(sampleRate, sampleRate*3/2, 660, 8000))
+	aSamples := adpcm.AppendSamples(nil, a.Blocks)
+	bSamples := adpcm.AppendSamples(nil, b.Blocks)
+
+	bs, err := os.ReadFile(path.Join("testdata", "KZ007.MAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kz007, err := NewDecoder(bytes.NewReader(bs)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	kz007Samples := adpcm.AppendSamples(nil, kz007.Blocks)
+
+	tests := []struct {
+		name string
+		edit func() (*Stream, error)
+		want []int16
+	}{
+		{
+			name: "trim",
+			edit: func() (*Stream, error) { return a.Trim(500*time.Millisecond, 1500*time.Millisecond) },
+			want: aSamples[sampleRate/2 : sampleRate*3/2],
+		},
+		{
+			name: "trim whole stream",
+			edit: func() (*Stream, error) { return a.Trim(0, a.Duration()) },
+			want: aSamples,
+		},
+		{
+			name: "trim game audio",
+			edit: func() (*Stream, error) { return kz007.Trim(time.Second, 2*time.Second) },
+			want: kz007Samples[sampleRate : sampleRate*2],
+		},
+		{
+			name: "concat",
+			edit: func() (*Stream, error) { return Concat(a, b, a), nil },
+			want: append(append(append([]int16(nil), aSamples...), bSamples...), aSamples...),
+		},
+		{
+			name: "mix",
+			edit: func() (*Stream, error) { return Mix(b, a, 0.5), nil },
+			want: internalaudio.Mix(bSamples, aSamples, 0.5),
+		},
+		{
+			name: "fade in",
+			edit: func() (*Stream, error) { return a.FadeIn(time.Second), nil },
+			want: internalaudio.FadeIn(aSamples, sampleRate),
+		},
+		{
+			name: "fade out",
+			edit: func() (*Stream, error) { return a.FadeOut(time.Second), nil },
+			want: internalaudio.FadeOut(aSamples, sampleRate),
+		},
+		{
+			name: "fade in negative duration",
+			edit: func() (*Stream, error) { return a.FadeIn(-time.Millisecond), nil },
+			want: aSamples,
+		},
+		{
+			name: "fade out negative duration",
+			edit: func() (*Stream, error) { return a.FadeOut(-time.Millisecond), nil },
+			want: aSamples,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.edit()
+			if err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+
+			// The stream must survive a round trip through the encoder.
+			encoded := &bytes.Buffer{}
+			if err := NewEncoder(encoded).Encode(s); err != nil {
+				t.Fatalf("Encode() error = %v, want nil", err)
+			}
+			decoded, err := NewDecoder(encoded).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			got := adpcm.AppendSamples(nil, decoded.Blocks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("decoded %d sample(s), want %d", len(got), len(tt.want))
+			}
+
+			if snr := audiotest.SignalToNoise(tt.want[audiotest.Warmup:], got[audiotest.Warmup:]); snr < 25 {
+				t.Errorf("signal-to-noise ratio = %.1f dB, want at least 25 dB", snr)
+			}
+		})
+	}
+}
+
+func TestTrim_invalid(t *testing.T) {
+	s := FromPCM(audiotest.Tone(sampleRate, sampleRate, 440, 8000))
+	tests := []struct {
+		name       string
+		start, end time.Duration
+	}{
+		{name: "negative start", start: -time.Second, end: 500 * time.Millisecond},
+		{name: "end before start", start: 500 * time.Millisecond, end: 250 * time.Millisecond},
+		{name: "end after stream", start: 0, end: 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Trim(tt.start, tt.end); err == nil {
+				t.Error("Trim() error = nil, want error")
+			}
+		})
+	}
+}
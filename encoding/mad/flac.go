@@ -0,0 +1,14 @@
+package mad
+
+import (
+	"io"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+)
+
+// EncodeToFLAC writes the stream to w as a lossless 16-bit FLAC file. Unlike
+// EncodeToWAV, w does not need to be seekable.
+func (s *Stream) EncodeToFLAC(w io.Writer) error {
+	return internalaudio.WriteFLAC(w, sampleRate, [][]int16{adpcm.AppendSamples(nil, s.Blocks)})
+}
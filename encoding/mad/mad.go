@@ -4,12 +4,26 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"time"
 
-	"github.com/jonathaningram/dark-omen/internal/audio"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/internal/readerutil"
+)
+
+const (
+	// sampleRate is the sample rate of the game's audio files, in Hz.
+	sampleRate = 22050
+
+	// adpcmBlockSize is the number of bytes of ADPCM data in each block, which
+	// holds two samples per byte.
+	adpcmBlockSize = 1020
+	// sentinelIndex is the index in the header that follows the last ADPCM
+	// block. The remainder of the stream is PCM data.
+	sentinelIndex = 99
 )
 
 type Stream struct {
-	Blocks []audio.Block
+	Blocks []adpcm.AnyBlock
 
 	// Note: Storing these so that a re-encoded stream is correct. sample 99 is
 	// always a different value and index 99 is always equal to 99. Not sure if
@@ -23,6 +37,103 @@ func (s *Stream) Channels() int {
 	return 1
 }
 
+// SampleRate returns the sample rate of the stream, in Hz.
+func (s *Stream) SampleRate() int {
+	return sampleRate
+}
+
+// NumSamples returns the number of samples in the stream. It is computed from
+// the size of each block, so no blocks are decoded.
+func (s *Stream) NumSamples() int {
+	var n int
+	for _, b := range s.Blocks {
+		n += b.NumSamples()
+	}
+	return n
+}
+
+// Duration returns the length of the stream when played.
+func (s *Stream) Duration() time.Duration {
+	return duration(s.NumSamples())
+}
+
+// Config holds the format and length of a MAD audio stream.
+type Config struct {
+	SampleRate int
+	Channels   int
+	NumSamples int
+}
+
+// Duration returns the length of the stream when played.
+func (c Config) Duration() time.Duration {
+	return duration(c.NumSamples)
+}
+
+func duration(samples int) time.Duration {
+	return time.Duration(samples) * time.Second / sampleRate
+}
+
+// DecodeConfig returns the format and length of the MAD audio stream read from
+// r without decoding its blocks. Only the header of each block is read; if r is
+// an io.Seeker, the rest of each block is seeked past rather than read.
+func DecodeConfig(r io.Reader) (Config, error) {
+	c := Config{
+		SampleRate: sampleRate,
+		Channels:   1,
+	}
+
+	for {
+		var bs [4]byte
+		if _, err := io.ReadFull(r, bs[:]); err != nil {
+			// Some MAD streams don't have a trailing PCM block, so if we
+			// encounter EOF, we are done.
+			if err == io.EOF {
+				return c, nil
+			}
+			return Config{}, fmt.Errorf("could not read sample and index data: %w", err)
+		}
+		index := int16(binary.LittleEndian.Uint16(bs[2:4]))
+
+		if index == sentinelIndex {
+			break
+		}
+
+		if err := readerutil.Skip(r, adpcmBlockSize); err != nil {
+			return Config{}, fmt.Errorf("could not read mono ADPCM data: %w", err)
+		}
+		c.NumSamples += adpcmBlockSize * 2
+	}
+
+	n, err := readerutil.Remaining(r)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read PCM data: %w", err)
+	}
+	c.NumSamples += int(n / 2)
+
+	return c, nil
+}
+
+// FromPCM returns a new stream that encodes the mono 16-bit PCM samples as IMA
+// ADPCM blocks. Samples that do not fill a whole block are stored as PCM at the
+// end of the stream, as in the game's audio files.
+func FromPCM(samples []int16) *Stream {
+	s := &Stream{
+		Blocks: make([]adpcm.AnyBlock, 0, len(samples)/(adpcmBlockSize*2)+1),
+	}
+
+	enc := &adpcm.Encoder{}
+	for len(samples) >= adpcmBlockSize*2 {
+		s.Blocks = append(s.Blocks, enc.EncodeBlock(samples[:adpcmBlockSize*2]))
+		samples = samples[adpcmBlockSize*2:]
+	}
+
+	s.sample99 = enc.Sample()
+	s.index99 = sentinelIndex
+	s.Blocks = append(s.Blocks, adpcm.NewPCM16BlockFromInt16Slice(append([]int16(nil), samples...)))
+
+	return s
+}
+
 // Decoder reads and decodes a MAD audio stream from an input stream.
 type Decoder struct {
 	r io.Reader
@@ -37,7 +148,7 @@ func NewDecoder(r io.Reader) *Decoder {
 // new stream containing decoded blocks.
 func (d *Decoder) Decode() (*Stream, error) {
 	s := &Stream{
-		Blocks: make([]audio.Block, 0),
+		Blocks: make([]adpcm.AnyBlock, 0),
 	}
 
 	for {
@@ -53,23 +164,22 @@ func (d *Decoder) Decode() (*Stream, error) {
 		sample := int16(binary.LittleEndian.Uint16(bs[0:2]))
 		index := int16(binary.LittleEndian.Uint16(bs[2:4]))
 
-		if index == 99 {
+		if index == sentinelIndex {
 			s.sample99 = sample
 			s.index99 = index
 			break
 		}
 
-		const size = 1020
-		monoData := make([]byte, size)
+		monoData := make([]byte, adpcmBlockSize)
 		n, err := d.r.Read(monoData)
-		if n != size {
-			return nil, fmt.Errorf("could not read mono ADPCM data: read %d byte(s), expected %d", n, size)
+		if n != adpcmBlockSize {
+			return nil, fmt.Errorf("could not read mono ADPCM data: read %d byte(s), expected %d", n, adpcmBlockSize)
 		}
 		if err != nil && err != io.EOF {
 			return nil, err
 		}
 
-		s.Blocks = append(s.Blocks, audio.NewADPCMBlock(sample, index, monoData))
+		s.Blocks = append(s.Blocks, adpcm.NewBlock(sample, index, monoData))
 	}
 
 	// Read remaining bytes.
@@ -77,11 +187,7 @@ func (d *Decoder) Decode() (*Stream, error) {
 	if err != nil {
 		return nil, err
 	}
-	b, err := audio.NewPCM16Block(buf)
-	if err != nil {
-		return nil, err
-	}
-	s.Blocks = append(s.Blocks, b)
+	s.Blocks = append(s.Blocks, adpcm.NewPCM16Block(buf))
 
 	return s, nil
 }
@@ -100,7 +206,7 @@ func NewEncoder(w io.Writer) *Encoder {
 func (e *Encoder) Encode(s *Stream) error {
 	for i := 0; i < len(s.Blocks); i++ {
 		switch b := s.Blocks[i].(type) {
-		case *audio.ADPCMBlock:
+		case *adpcm.Block:
 			if err := e.encodeADPCMBlock(b); err != nil {
 				return err
 			}
@@ -126,7 +232,7 @@ func (e *Encoder) Encode(s *Stream) error {
 	return nil
 }
 
-func (e *Encoder) encodeADPCMBlock(b *audio.ADPCMBlock) error {
+func (e *Encoder) encodeADPCMBlock(b *adpcm.Block) error {
 	if err := binary.Write(e.w, binary.LittleEndian, b.Sample()); err != nil {
 		return err
 	}
@@ -141,7 +247,7 @@ func (e *Encoder) encodeADPCMBlock(b *audio.ADPCMBlock) error {
 	return err
 }
 
-func (e *Encoder) encodePCM16Block(b audio.Block) error {
+func (e *Encoder) encodePCM16Block(b adpcm.AnyBlock) error {
 	bs, err := b.Bytes()
 	if err != nil {
 		return err
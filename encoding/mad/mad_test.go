@@ -3,11 +3,19 @@ package mad
 import (
 	"bytes"
 	"io"
+	"math"
 	"os"
 	"path"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-audio/wav"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+	"github.com/mewkiz/flac"
 )
 
 var stream *Stream
@@ -162,9 +170,256 @@ func TestEncodeToWAV(t *testing.T) {
 	}
 }
 
+func TestEncodeToFLAC(t *testing.T) {
+	tests := []string{
+		"A_AYESIR.MAD",
+		"A_ITEM.MAD",
+		"KZ007.MAD",
+		"T_KZ071.MAD",
+		"U_AYE.MAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			f, err := os.Open(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			stream, err := NewDecoder(f).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			var buf bytes.Buffer
+			if err := stream.EncodeToFLAC(&buf); err != nil {
+				t.Fatalf("EncodeToFLAC() error = %v, want nil", err)
+			}
+
+			fs, err := flac.New(&buf)
+			if err != nil {
+				t.Fatalf("flac.New() error = %v, want nil", err)
+			}
+			if fs.Info.SampleRate != 22050 || fs.Info.NChannels != 1 || fs.Info.BitsPerSample != 16 {
+				t.Errorf("got FLAC stream info = %+v, want 22050 Hz 16-bit mono", fs.Info)
+			}
+			var got []int16
+			for {
+				frame, err := fs.ParseNext()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ParseNext() error = %v, want nil", err)
+				}
+				for _, v := range frame.Subframes[0].Samples {
+					got = append(got, int16(v))
+				}
+			}
+
+			// FLAC is lossless, so the samples must match the decoded stream
+			// exactly.
+			want := adpcm.AppendSamples(nil, stream.Blocks)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("FLAC samples mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func truncateBytes(bs []byte, size int) []byte {
 	if len(bs) > size {
 		return bs[:size]
 	}
 	return bs
 }
+
+func TestFromPCM(t *testing.T) {
+	// A 440 Hz tone that fills several blocks and leaves a partial block of
+	// PCM samples at the end.
+	samples := audiotest.Tone(sampleRate, 2040*4+123, 440, 8000)
+
+	encoded := &bytes.Buffer{}
+	if err := NewEncoder(encoded).Encode(FromPCM(samples)); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+
+	stream, err := NewDecoder(encoded).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+
+	var got []int16
+	for _, b := range stream.Blocks {
+		got = append(got, b.AsPCM16Block().Data...)
+	}
+	if len(got) != len(samples) {
+		t.Fatalf("decoded %d sample(s), want %d", len(got), len(samples))
+	}
+
+	// IMA ADPCM should give a signal-to-noise ratio of better than 30 dB for a
+	// pure tone once the step index has adapted to it.
+	if snr := audiotest.SignalToNoise(samples[audiotest.Warmup:], got[audiotest.Warmup:]); snr < 30 {
+		t.Errorf("signal-to-noise ratio = %.1f dB, want at least 30 dB", snr)
+	}
+
+	// The trailing PCM samples are stored exactly.
+	if diff := cmp.Diff(samples[2040*4:], got[2040*4:]); diff != "" {
+		t.Errorf("PCM samples mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeWAV(t *testing.T) {
+	tests := []string{
+		"A_AYESIR.WAV",
+		"KZ007.WAV",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			f, err := os.Open(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			want, err := wav.NewDecoder(f).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := DecodeWAV(f)
+			if err != nil {
+				t.Fatalf("DecodeWAV() error = %v, want nil", err)
+			}
+
+			encoded := &bytes.Buffer{}
+			if err := NewEncoder(encoded).Encode(s); err != nil {
+				t.Fatalf("Encode() error = %v, want nil", err)
+			}
+			stream, err := NewDecoder(encoded).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			var got []int16
+			for _, b := range stream.Blocks {
+				got = append(got, b.AsPCM16Block().Data...)
+			}
+			if len(got) != len(want.Data) {
+				t.Fatalf("decoded %d sample(s), want %d", len(got), len(want.Data))
+			}
+
+			var signal, noise float64
+			for i, v := range want.Data {
+				s := float64(v)
+				n := float64(got[i]) - s
+				signal += s * s
+				noise += n * n
+			}
+			if snr := 10 * math.Log10(signal/noise); snr < 20 {
+				t.Errorf("signal-to-noise ratio = %.1f dB, want at least 20 dB", snr)
+			}
+		})
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []string{
+		"A_AYESIR.MAD",
+		"A_ITEM.MAD",
+		"KZ007.MAD",
+		"T_KZ071.MAD",
+		"U_AYE.MAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := NewDecoder(bytes.NewReader(bs)).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+			var decoded int
+			for _, b := range s.Blocks {
+				decoded += len(b.AsPCM16Block().Data)
+			}
+			if got := s.NumSamples(); got != decoded {
+				t.Errorf("Stream.NumSamples() = %d, want %d", got, decoded)
+			}
+
+			want := Config{SampleRate: 22050, Channels: 1, NumSamples: decoded}
+
+			// Probe both a reader that can seek past blocks and one that
+			// cannot.
+			for _, r := range []io.Reader{bytes.NewReader(bs), bytes.NewBuffer(bs)} {
+				got, err := DecodeConfig(r)
+				if err != nil {
+					t.Fatalf("DecodeConfig() error = %v, want nil", err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("DecodeConfig() mismatch (-want +got):\n%s", diff)
+				}
+			}
+
+			if got, want := s.Duration(), time.Duration(decoded)*time.Second/22050; got != want {
+				t.Errorf("Stream.Duration() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeConfig_truncated(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "A_ITEM.MAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeConfig(bytes.NewReader(bs[:100])); err == nil {
+		t.Error("DecodeConfig() error = nil, want error")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []string{
+		"A_AYESIR.MAD",
+		"KZ007.MAD",
+		"U_AYE.MAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			f, err := os.Open(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			stream, err := NewDecoder(f).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			const target = -23
+			normalized, err := stream.Normalize(target)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v, want nil", err)
+			}
+			if got, want := normalized.NumSamples(), stream.NumSamples(); got != want {
+				t.Errorf("Normalize() has %d sample(s), want %d", got, want)
+			}
+
+			m, err := normalized.Loudness()
+			if err != nil {
+				t.Fatalf("Loudness() error = %v, want nil", err)
+			}
+			// Re-encoding as ADPCM adds a little noise.
+			if d := math.Abs(m.Integrated - target); d > 0.5 {
+				t.Errorf("Normalize() loudness = %.2f LUFS, want %.2f LUFS", m.Integrated, float64(target))
+			}
+		})
+	}
+}
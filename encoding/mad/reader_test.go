@@ -0,0 +1,190 @@
+package mad
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPCMReader(t *testing.T) {
+	tests := []string{
+		"A_AYESIR.MAD",
+		"A_ITEM.MAD",
+		"KZ007.MAD",
+		"T_KZ071.MAD",
+		"U_AYE.MAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := NewDecoder(bytes.NewReader(bs)).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+			var want []byte
+			for _, b := range s.Blocks {
+				for _, v := range b.AsPCM16Block().Data {
+					want = binary.LittleEndian.AppendUint16(want, uint16(v))
+				}
+			}
+
+			if err := iotest.TestReader(NewPCMReader(bytes.NewReader(bs)), want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPCMReader_truncated(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "A_ITEM.MAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = io.ReadAll(NewPCMReader(bytes.NewReader(bs[:100])))
+	if err == nil {
+		t.Error("ReadAll() error = nil, want error")
+	}
+}
+
+func BenchmarkPCMReader(b *testing.B) {
+	bs, err := os.ReadFile(path.Join("testdata", "T_KZ071.MAD"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := io.Copy(io.Discard, NewPCMReader(bytes.NewReader(bs))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []string{
+		"A_AYESIR.MAD",
+		"A_ITEM.MAD",
+		"KZ007.MAD",
+		"T_KZ071.MAD",
+		"U_AYE.MAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := io.ReadAll(NewPCMReader(bytes.NewReader(bs)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(bytes.NewReader(bs), int64(len(bs)))
+			if err != nil {
+				t.Fatalf("NewReader() error = %v, want nil", err)
+			}
+			if got := r.NumSamples(); got != len(want)/2 {
+				t.Errorf("Reader.NumSamples() = %d, want %d", got, len(want)/2)
+			}
+
+			if err := iotest.TestReader(r, want); err != nil {
+				t.Fatal(err)
+			}
+
+			// Seek to the start of a block, the middle of a block, the trailing
+			// PCM data and the last sample.
+			for _, sample := range []int64{0, 2040, 3000, int64(len(want)/2 - 100), int64(len(want)/2 - 1)} {
+				if _, err := r.Seek(sample*2, io.SeekStart); err != nil {
+					t.Fatalf("Seek(%d) error = %v, want nil", sample*2, err)
+				}
+				got := make([]byte, 200)
+				n, err := io.ReadFull(r, got)
+				if err != nil && err != io.ErrUnexpectedEOF {
+					t.Fatalf("ReadFull() after Seek(%d) error = %v, want nil", sample*2, err)
+				}
+				if !bytes.Equal(got[:n], want[sample*2:min(int(sample*2)+200, len(want))]) {
+					t.Errorf("ReadFull() after Seek(%d) returned the wrong bytes", sample*2)
+				}
+			}
+
+			// SeekSample seeks to the start of a sample, even from the middle of
+			// one.
+			for _, sample := range []int64{0, 2040, 3000, int64(len(want)/2 - 1)} {
+				if _, err := r.Seek(1, io.SeekStart); err != nil {
+					t.Fatalf("Seek(1) error = %v, want nil", err)
+				}
+				if err := r.SeekSample(sample); err != nil {
+					t.Fatalf("SeekSample(%d) error = %v, want nil", sample, err)
+				}
+				got := make([]byte, 2)
+				if _, err := io.ReadFull(r, got); err != nil {
+					t.Fatalf("ReadFull() after SeekSample(%d) error = %v, want nil", sample, err)
+				}
+				if !bytes.Equal(got, want[sample*2:sample*2+2]) {
+					t.Errorf("ReadFull() after SeekSample(%d) = %v, want %v", sample, got, want[sample*2:sample*2+2])
+				}
+			}
+			if err := r.SeekSample(-1); err == nil {
+				t.Error("SeekSample(-1) error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestNewReader_truncated(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "A_ITEM.MAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReader(bytes.NewReader(bs[:100]), 100); err == nil {
+		t.Error("NewReader() error = nil, want error")
+	}
+}
+
+func TestReaders_partialSample(t *testing.T) {
+	samples := make([]int16, 3000)
+	for i := range samples {
+		samples[i] = int16(i * 7)
+	}
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).Encode(FromPCM(samples)); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+	// A stray byte after the trailing PCM samples is not a whole sample.
+	bs := append(buf.Bytes(), 0x7F)
+
+	s, err := NewDecoder(bytes.NewReader(bs)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	var want []byte
+	for _, b := range s.Blocks {
+		want = b.AsPCM16Block().AppendBytes(want)
+	}
+	if got := len(want) / 2; got != len(samples) {
+		t.Fatalf("Decode() has %d sample(s), want %d", got, len(samples))
+	}
+
+	if err := iotest.TestReader(NewPCMReader(iotest.OneByteReader(bytes.NewReader(bs))), want); err != nil {
+		t.Errorf("PCMReader: %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(bs), int64(len(bs)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v, want nil", err)
+	}
+	if got := r.NumSamples(); got != len(samples) {
+		t.Errorf("Reader.NumSamples() = %d, want %d", got, len(samples))
+	}
+	if err := iotest.TestReader(r, want); err != nil {
+		t.Errorf("Reader: %v", err)
+	}
+}
@@ -3,23 +3,15 @@ package mad
 import (
 	"io"
 
-	"github.com/go-audio/audio"
 	"github.com/go-audio/wav"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
 )
 
 func (s *Stream) EncodeToWAV(w io.WriteSeeker) error {
-	const sampleRate = 22050
-
-	buf := &audio.IntBuffer{
-		Format: &audio.Format{
-			NumChannels: s.Channels(),
-			SampleRate:  sampleRate,
-		},
-	}
-	for _, block := range s.Blocks {
-		for _, v := range block.AsPCM16Block().Data {
-			buf.Data = append(buf.Data, int(v))
-		}
+	buf, err := adpcm.ToIntBuffer(sampleRate, s.Blocks)
+	if err != nil {
+		return err
 	}
 	enc := wav.NewEncoder(
 		w,
@@ -36,3 +28,14 @@ func (s *Stream) EncodeToWAV(w io.WriteSeeker) error {
 	}
 	return nil
 }
+
+// DecodeWAV reads the WAV file from r and returns a new stream that encodes it.
+// The audio is resampled to 22050 Hz, mixed down to mono and dithered to 16
+// bits if needed.
+func DecodeWAV(r io.ReadSeeker) (*Stream, error) {
+	samples, err := internalaudio.ReadWAV(r, sampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	return FromPCM(samples[0]), nil
+}
@@ -0,0 +1,114 @@
+package sad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/encoding/mad"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+)
+
+// The functions and methods in this file work like the editing functions of
+// package mad. An error is returned for a stream whose channels have a
+// different number of samples.
+
+// Trim returns a new stream holding the stream's audio from start to end.
+// Times are rounded to the nearest sample.
+func (s *Stream) Trim(start, end time.Duration) (*Stream, error) {
+	left, right, err := s.samples()
+	if err != nil {
+		return nil, err
+	}
+	i, j := internalaudio.NumSamples(sampleRate, start), internalaudio.NumSamples(sampleRate, end)
+	if i < 0 || j < i || j > len(left) {
+		return nil, fmt.Errorf("invalid range %v to %v of a %v stream", start, end, s.Duration())
+	}
+	return FromPCM(left[i:j], right[i:j])
+}
+
+// Concat returns a new stream holding the audio of each stream in turn.
+func Concat(streams ...*Stream) (*Stream, error) {
+	var left, right []int16
+	for _, s := range streams {
+		left = adpcm.AppendSamples(left, s.LeftBlocks)
+		right = adpcm.AppendSamples(right, s.RightBlocks)
+	}
+	return FromPCM(left, right)
+}
+
+// Mix returns a new stream holding the audio of a with that of b, scaled by
+// gain, added to it. The stream is as long as the longer of a and b. Samples
+// that would exceed 16 bits are clipped.
+func Mix(a, b *Stream, gain float64) (*Stream, error) {
+	aLeft, aRight, err := a.samples()
+	if err != nil {
+		return nil, err
+	}
+	bLeft, bRight, err := b.samples()
+	if err != nil {
+		return nil, err
+	}
+	return FromPCM(
+		internalaudio.Mix(aLeft, bLeft, gain),
+		internalaudio.Mix(aRight, bRight, gain),
+	)
+}
+
+// FadeIn returns a new stream holding the stream's audio with a linear fade
+// from silence over its first d. The whole stream is faded if it is shorter
+// than d, and none of it is faded if d is not positive.
+func (s *Stream) FadeIn(d time.Duration) (*Stream, error) {
+	left, right, err := s.samples()
+	if err != nil {
+		return nil, err
+	}
+	n := internalaudio.NumSamples(sampleRate, d)
+	return FromPCM(internalaudio.FadeIn(left, n), internalaudio.FadeIn(right, n))
+}
+
+// FadeOut returns a new stream holding the stream's audio with a linear fade
+// to silence over its last d. The whole stream is faded if it is shorter than
+// d, and none of it is faded if d is not positive.
+func (s *Stream) FadeOut(d time.Duration) (*Stream, error) {
+	left, right, err := s.samples()
+	if err != nil {
+		return nil, err
+	}
+	n := internalaudio.NumSamples(sampleRate, d)
+	return FromPCM(internalaudio.FadeOut(left, n), internalaudio.FadeOut(right, n))
+}
+
+// ToMono returns a new MAD stream holding the average of the stream's left
+// and right channels.
+func (s *Stream) ToMono() (*mad.Stream, error) {
+	left, right, err := s.samples()
+	if err != nil {
+		return nil, err
+	}
+	mono := make([]int16, len(left))
+	for i := range mono {
+		mono[i] = int16((int(left[i]) + int(right[i])) >> 1)
+	}
+	return mad.FromPCM(mono), nil
+}
+
+// ToStereo returns a new stream with the audio of the mono MAD stream in both
+// channels. It is a function of this package rather than a method of
+// mad.Stream because this package depends on package mad.
+func ToStereo(m *mad.Stream) *Stream {
+	samples := adpcm.AppendSamples(nil, m.Blocks)
+	// The channels have the same number of samples, so there is no error.
+	s, _ := FromPCM(samples, samples)
+	return s
+}
+
+// samples returns the decoded samples of the left and right channels.
+func (s *Stream) samples() (left, right []int16, err error) {
+	left = adpcm.AppendSamples(nil, s.LeftBlocks)
+	right = adpcm.AppendSamples(nil, s.RightBlocks)
+	if len(left) != len(right) {
+		return nil, nil, fmt.Errorf("left channel has %d sample(s), right channel has %d", len(left), len(right))
+	}
+	return left, right, nil
+}
@@ -0,0 +1,206 @@
+package sad
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/encoding/mad"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+)
+
+func TestEdit(t *testing.T) {
+	a, err := FromPCM(audiotest.Tone(sampleRate, sampleRate*2, 440, 8000), audiotest.Tone(sampleRate, sampleRate*2, 550, 8000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := FromPCM(audiotest.Tone(sampleRate, sampleRate*3/2, 660, 8000), audiotest.Tone(sampleRate, sampleRate*3/2, 770, 8000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aLeft, aRight, _ := a.samples()
+	bLeft, bRight, _ := b.samples()
+
+	bs, err := os.ReadFile(path.Join("testdata", "1BOUN001.SAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	boun, err := NewDecoder(bytes.NewReader(bs)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	bounLeft, bounRight, _ := boun.samples()
+
+	concat := func(samples ...[]int16) []int16 {
+		var out []int16
+		for _, s := range samples {
+			out = append(out, s...)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		edit        func() (*Stream, error)
+		left, right []int16
+	}{
+		{
+			name:  "trim",
+			edit:  func() (*Stream, error) { return a.Trim(500*time.Millisecond, 1500*time.Millisecond) },
+			left:  aLeft[sampleRate/2 : sampleRate*3/2],
+			right: aRight[sampleRate/2 : sampleRate*3/2],
+		},
+		{
+			name:  "trim game audio",
+			edit:  func() (*Stream, error) { return boun.Trim(time.Second, 2*time.Second) },
+			left:  bounLeft[sampleRate : sampleRate*2],
+			right: bounRight[sampleRate : sampleRate*2],
+		},
+		{
+			name:  "concat",
+			edit:  func() (*Stream, error) { return Concat(a, b, a) },
+			left:  concat(aLeft, bLeft, aLeft),
+			right: concat(aRight, bRight, aRight),
+		},
+		{
+			name:  "mix",
+			edit:  func() (*Stream, error) { return Mix(b, a, 0.5) },
+			left:  internalaudio.Mix(bLeft, aLeft, 0.5),
+			right: internalaudio.Mix(bRight, aRight, 0.5),
+		},
+		{
+			name:  "fade in",
+			edit:  func() (*Stream, error) { return a.FadeIn(time.Second) },
+			left:  internalaudio.FadeIn(aLeft, sampleRate),
+			right: internalaudio.FadeIn(aRight, sampleRate),
+		},
+		{
+			name:  "fade out",
+			edit:  func() (*Stream, error) { return a.FadeOut(time.Second) },
+			left:  internalaudio.FadeOut(aLeft, sampleRate),
+			right: internalaudio.FadeOut(aRight, sampleRate),
+		},
+		{
+			name:  "fade in negative duration",
+			edit:  func() (*Stream, error) { return a.FadeIn(-time.Millisecond) },
+			left:  aLeft,
+			right: aRight,
+		},
+		{
+			name:  "fade out negative duration",
+			edit:  func() (*Stream, error) { return a.FadeOut(-time.Millisecond) },
+			left:  aLeft,
+			right: aRight,
+		},
+		{
+			name: "to stereo",
+			edit: func() (*Stream, error) {
+				return ToStereo(mad.FromPCM(audiotest.Tone(sampleRate, sampleRate, 440, 8000))), nil
+			},
+			left:  audiotest.Tone(sampleRate, sampleRate, 440, 8000),
+			right: audiotest.Tone(sampleRate, sampleRate, 440, 8000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.edit()
+			if err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+
+			// The stream must survive a round trip through the encoder.
+			encoded := &bytes.Buffer{}
+			if err := NewEncoder(encoded).Encode(s); err != nil {
+				t.Fatalf("Encode() error = %v, want nil", err)
+			}
+			decoded, err := NewDecoder(encoded).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			left, right, err := decoded.samples()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(left) != len(tt.left) {
+				t.Fatalf("decoded %d sample(s), want %d", len(left), len(tt.left))
+			}
+			if snr := audiotest.SignalToNoise(tt.left[audiotest.Warmup:], left[audiotest.Warmup:]); snr < 25 {
+				t.Errorf("left signal-to-noise ratio = %.1f dB, want at least 25 dB", snr)
+			}
+			if snr := audiotest.SignalToNoise(tt.right[audiotest.Warmup:], right[audiotest.Warmup:]); snr < 25 {
+				t.Errorf("right signal-to-noise ratio = %.1f dB, want at least 25 dB", snr)
+			}
+		})
+	}
+}
+
+func TestToMono(t *testing.T) {
+	s, err := FromPCM(audiotest.Tone(sampleRate, sampleRate, 440, 8000), audiotest.Tone(sampleRate, sampleRate, 440, 4000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := s.ToMono()
+	if err != nil {
+		t.Fatalf("ToMono() error = %v, want nil", err)
+	}
+
+	encoded := &bytes.Buffer{}
+	if err := mad.NewEncoder(encoded).Encode(m); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+	decoded, err := mad.NewDecoder(encoded).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+
+	got := adpcm.AppendSamples(nil, decoded.Blocks)
+	want := audiotest.Tone(sampleRate, sampleRate, 440, 6000)
+	if len(got) != len(want) {
+		t.Fatalf("decoded %d sample(s), want %d", len(got), len(want))
+	}
+	if snr := audiotest.SignalToNoise(want[audiotest.Warmup:], got[audiotest.Warmup:]); snr < 25 {
+		t.Errorf("signal-to-noise ratio = %.1f dB, want at least 25 dB", snr)
+	}
+}
+
+func TestEdit_invalid(t *testing.T) {
+	s, err := FromPCM(audiotest.Tone(sampleRate, sampleRate, 440, 8000), audiotest.Tone(sampleRate, sampleRate, 440, 8000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatched := &Stream{
+		LeftBlocks:  []adpcm.AnyBlock{adpcm.NewPCM16BlockFromInt16Slice(make([]int16, 10))},
+		RightBlocks: []adpcm.AnyBlock{adpcm.NewPCM16BlockFromInt16Slice(make([]int16, 5))},
+	}
+
+	tests := []struct {
+		name string
+		edit func() (*Stream, error)
+	}{
+		{name: "trim negative start", edit: func() (*Stream, error) { return s.Trim(-time.Second, 0) }},
+		{name: "trim end before start", edit: func() (*Stream, error) { return s.Trim(500*time.Millisecond, 0) }},
+		{name: "trim end after stream", edit: func() (*Stream, error) { return s.Trim(0, 2*time.Second) }},
+		{name: "trim mismatched channels", edit: func() (*Stream, error) { return mismatched.Trim(0, 0) }},
+		{name: "concat mismatched channels", edit: func() (*Stream, error) { return Concat(s, mismatched) }},
+		{name: "mix mismatched channels", edit: func() (*Stream, error) { return Mix(s, mismatched, 1) }},
+		{name: "fade in mismatched channels", edit: func() (*Stream, error) { return mismatched.FadeIn(time.Second) }},
+		{name: "fade out mismatched channels", edit: func() (*Stream, error) { return mismatched.FadeOut(time.Second) }},
+		{name: "to mono mismatched channels", edit: func() (*Stream, error) {
+			_, err := mismatched.ToMono()
+			return nil, err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.edit(); err == nil {
+				t.Error("error = nil, want error")
+			}
+		})
+	}
+}
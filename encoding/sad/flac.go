@@ -0,0 +1,20 @@
+package sad
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+)
+
+// EncodeToFLAC writes the stream to w as a lossless 16-bit stereo FLAC file.
+// Unlike EncodeToWAV, w does not need to be seekable.
+func (s *Stream) EncodeToFLAC(w io.Writer) error {
+	left := adpcm.AppendSamples(nil, s.LeftBlocks)
+	right := adpcm.AppendSamples(nil, s.RightBlocks)
+	if len(left) != len(right) {
+		return fmt.Errorf("left channel has %d sample(s), right channel has %d", len(left), len(right))
+	}
+	return internalaudio.WriteFLAC(w, sampleRate, [][]int16{left, right})
+}
@@ -0,0 +1,26 @@
+package sad
+
+import (
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/audio/loudness"
+)
+
+// Loudness returns the peak, RMS and integrated loudness of the stream.
+func (s *Stream) Loudness() (loudness.Measurement, error) {
+	return loudness.MeasureBlocks(sampleRate, s.LeftBlocks, s.RightBlocks)
+}
+
+// Normalize returns a new stream that encodes the stream's audio with a gain
+// applied so that its integrated loudness is target LUFS. Samples that would
+// clip are limited to 16 bits. It returns loudness.ErrNoLoudness if the
+// stream is too short or too quiet to measure.
+func (s *Stream) Normalize(target float64) (*Stream, error) {
+	samples, err := loudness.Normalize(sampleRate, target,
+		adpcm.AppendSamples(nil, s.LeftBlocks),
+		adpcm.AppendSamples(nil, s.RightBlocks),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return FromPCM(samples[0], samples[1])
+}
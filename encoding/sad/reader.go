@@ -0,0 +1,254 @@
+package sad
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
+	"github.com/jonathaningram/dark-omen/internal/readerutil"
+)
+
+// PCMReader decodes a SAD audio stream incrementally. It reads one block at a
+// time from its input, so it starts producing samples immediately and its
+// memory use does not depend on the length of the stream.
+type PCMReader struct {
+	r io.Reader
+
+	// trailing is whether the sentinel has been read, after which the rest of
+	// the input is interleaved PCM data, which is read from pcm.
+	trailing bool
+	pcm      io.Reader
+
+	data [adpcmBlockSize * 2]byte
+	dec  internalaudio.BlockDecoder
+	// buf holds the decoded bytes of the current block and off is the number
+	// of them that have been read.
+	buf []byte
+	off int
+}
+
+// NewPCMReader returns a new reader that decodes the SAD audio stream read from
+// r.
+func NewPCMReader(r io.Reader) *PCMReader {
+	return &PCMReader{r: r}
+}
+
+// Read reads the decoded audio into p as interleaved left and right 16-bit
+// little-endian PCM samples.
+func (r *PCMReader) Read(p []byte) (n int, err error) {
+	if r.trailing {
+		// The trailing PCM data is already in the decoded format.
+		return r.pcm.Read(p)
+	}
+
+	for r.off == len(r.buf) {
+		if err := r.readBlock(); err != nil {
+			return 0, err
+		}
+		if r.trailing {
+			return r.pcm.Read(p)
+		}
+	}
+
+	n = copy(p, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// readBlock reads and decodes the next ADPCM block into buf.
+func (r *PCMReader) readBlock() error {
+	var bs [8]byte
+	if _, err := io.ReadFull(r.r, bs[:]); err != nil {
+		// Like Decoder, require the sentinel: unlike MAD streams, SAD streams
+		// always end with one.
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("could not read stereo sample and index data: %w", err)
+	}
+	leftSample := int16(binary.LittleEndian.Uint16(bs[0:2]))
+	leftIndex := int16(binary.LittleEndian.Uint16(bs[2:4]))
+	rightSample := int16(binary.LittleEndian.Uint16(bs[4:6]))
+	rightIndex := int16(binary.LittleEndian.Uint16(bs[6:8]))
+
+	if leftIndex == sentinelIndex && rightIndex == sentinelIndex {
+		r.trailing = true
+		// Like Decoder, drop a partial left and right sample pair at the end of
+		// the stream.
+		r.pcm = internalaudio.NewFrameReader(r.r, frameSize)
+		return nil
+	}
+
+	if _, err := io.ReadFull(r.r, r.data[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("could not read stereo ADPCM data: %w", err)
+	}
+
+	r.buf = r.dec.Decode([]int16{leftSample, rightSample}, []int16{leftIndex, rightIndex}, r.data[:])
+	r.off = 0
+
+	return nil
+}
+
+// blockSize is the size of each stereo ADPCM block in the stream, including its
+// header.
+const blockSize = 8 + adpcmBlockSize*2
+
+// decodedBlockSize is the size of each stereo ADPCM block once it has been
+// decoded to interleaved 16-bit PCM.
+const decodedBlockSize = adpcmBlockSize * 2 * 2 * 2
+
+// frameSize is the size of a decoded left and right sample pair.
+const frameSize = 4
+
+// Reader decodes a SAD audio stream with random access. Each ADPCM block
+// starts with its own initial samples and step indexes, so any block can be
+// decoded without decoding the blocks before it. This lets Seek jump to any
+// position in constant time.
+type Reader struct {
+	r io.ReaderAt
+
+	// blocks is the number of ADPCM blocks, pcmOffset is the offset of the
+	// trailing PCM data and pcmSize is its size, excluding a partial left and
+	// right sample pair at the end of the stream.
+	blocks    int64
+	pcmOffset int64
+	pcmSize   int64
+
+	pos int64
+
+	// block is the index of the block that is decoded in buf, or -1 if no
+	// block has been decoded.
+	block int64
+	data  [blockSize]byte
+	dec   internalaudio.BlockDecoder
+	buf   []byte
+}
+
+// NewReader returns a new reader that decodes the SAD audio stream read from r,
+// which is size bytes long. The header of each block is read to find where the
+// ADPCM blocks end.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	rd := &Reader{r: r, block: -1}
+
+	for off := int64(0); ; off += blockSize {
+		var bs [8]byte
+		if err := readerutil.ReadFullAt(r, bs[:], off); err != nil {
+			return nil, fmt.Errorf("could not read stereo sample and index data of block %d: %w", rd.blocks, err)
+		}
+		leftIndex := int16(binary.LittleEndian.Uint16(bs[2:4]))
+		rightIndex := int16(binary.LittleEndian.Uint16(bs[6:8]))
+		if leftIndex == sentinelIndex && rightIndex == sentinelIndex {
+			rd.pcmOffset = off + int64(len(bs))
+			break
+		}
+
+		if off+blockSize > size {
+			return nil, fmt.Errorf("could not read stereo ADPCM data of block %d: %w", rd.blocks, io.ErrUnexpectedEOF)
+		}
+		rd.blocks++
+	}
+	rd.pcmSize = size - rd.pcmOffset
+	rd.pcmSize -= rd.pcmSize % frameSize
+
+	return rd, nil
+}
+
+// Size returns the size of the decoded audio, in bytes.
+func (r *Reader) Size() int64 {
+	return r.blocks*decodedBlockSize + r.pcmSize
+}
+
+// NumSamples returns the number of samples in each channel of the stream.
+func (r *Reader) NumSamples() int {
+	return int(r.Size() / frameSize)
+}
+
+// Read reads the decoded audio into p as interleaved left and right 16-bit
+// little-endian PCM samples.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if r.pos >= r.Size() {
+		return 0, io.EOF
+	}
+
+	if k := r.pos / decodedBlockSize; k < r.blocks {
+		buf, err := r.decodeBlock(k)
+		if err != nil {
+			return 0, err
+		}
+		n = copy(p, buf[r.pos-k*decodedBlockSize:])
+		r.pos += int64(n)
+		return n, nil
+	}
+
+	// The trailing PCM data is already in the decoded format.
+	off := r.pos - r.blocks*decodedBlockSize
+	p = p[:min(int64(len(p)), r.pcmSize-off)]
+	n, err = r.r.ReadAt(p, r.pcmOffset+off)
+	r.pos += int64(n)
+	if n == len(p) {
+		return n, nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek sets the offset, in bytes of decoded audio, for the next Read. Each
+// left and right sample pair is 4 bytes, so sample n of each channel starts at
+// offset 4n. The offset does not have to be at the start of a sample pair, in
+// which case the next Read starts partway through a pair. Use SeekSample to
+// seek to the start of a sample pair.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.pos + offset
+	case io.SeekEnd:
+		abs = r.Size() + offset
+	default:
+		return 0, errors.New("sad.Reader.Seek: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("sad.Reader.Seek: negative position")
+	}
+	r.pos = abs
+	return abs, nil
+}
+
+// SeekSample sets the position for the next Read to the start of sample n of
+// each channel.
+func (r *Reader) SeekSample(n int64) error {
+	if n < 0 {
+		return errors.New("sad.Reader.SeekSample: negative position")
+	}
+	r.pos = n * frameSize
+	return nil
+}
+
+// decodeBlock returns the decoded bytes of the ADPCM block at index k.
+func (r *Reader) decodeBlock(k int64) ([]byte, error) {
+	if k == r.block {
+		return r.buf, nil
+	}
+
+	if err := readerutil.ReadFullAt(r.r, r.data[:], k*blockSize); err != nil {
+		return nil, fmt.Errorf("could not read block %d: %w", k, err)
+	}
+	leftSample := int16(binary.LittleEndian.Uint16(r.data[0:2]))
+	leftIndex := int16(binary.LittleEndian.Uint16(r.data[2:4]))
+	rightSample := int16(binary.LittleEndian.Uint16(r.data[4:6]))
+	rightIndex := int16(binary.LittleEndian.Uint16(r.data[6:8]))
+
+	r.buf = r.dec.Decode([]int16{leftSample, rightSample}, []int16{leftIndex, rightIndex}, r.data[8:])
+	r.block = k
+
+	return r.buf, nil
+}
@@ -0,0 +1,217 @@
+package sad
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPCMReader(t *testing.T) {
+	tests := []string{
+		"1BOUN001.SAD",
+		"1CHAS001.SAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := NewDecoder(bytes.NewReader(bs)).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+			var want []byte
+			for i := range s.LeftBlocks {
+				left := s.LeftBlocks[i].AsPCM16Block().Data
+				right := s.RightBlocks[i].AsPCM16Block().Data
+				for j := range left {
+					want = binary.LittleEndian.AppendUint16(want, uint16(left[j]))
+					want = binary.LittleEndian.AppendUint16(want, uint16(right[j]))
+				}
+			}
+
+			if err := iotest.TestReader(NewPCMReader(bytes.NewReader(bs)), want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPCMReader_truncated(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "1BOUN001.SAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = io.ReadAll(NewPCMReader(bytes.NewReader(bs[:100])))
+	if err == nil {
+		t.Error("ReadAll() error = nil, want error")
+	}
+}
+
+func BenchmarkPCMReader(b *testing.B) {
+	bs, err := os.ReadFile(path.Join("testdata", "1CHAS001.SAD"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := io.Copy(io.Discard, NewPCMReader(bytes.NewReader(bs))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []string{
+		"1BOUN001.SAD",
+		"1CHAS001.SAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := io.ReadAll(NewPCMReader(bytes.NewReader(bs)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(bytes.NewReader(bs), int64(len(bs)))
+			if err != nil {
+				t.Fatalf("NewReader() error = %v, want nil", err)
+			}
+			if got := r.NumSamples(); got != len(want)/4 {
+				t.Errorf("Reader.NumSamples() = %d, want %d", got, len(want)/4)
+			}
+
+			if err := iotest.TestReader(r, want); err != nil {
+				t.Fatal(err)
+			}
+
+			// Seek to the start of a block, the middle of a block, the trailing
+			// PCM data and the last sample.
+			for _, sample := range []int64{0, 1016, 1500, int64(len(want)/4 - 100), int64(len(want)/4 - 1)} {
+				if _, err := r.Seek(sample*4, io.SeekStart); err != nil {
+					t.Fatalf("Seek(%d) error = %v, want nil", sample*4, err)
+				}
+				got := make([]byte, 400)
+				n, err := io.ReadFull(r, got)
+				if err != nil && err != io.ErrUnexpectedEOF {
+					t.Fatalf("ReadFull() after Seek(%d) error = %v, want nil", sample*4, err)
+				}
+				if !bytes.Equal(got[:n], want[sample*4:min(int(sample*4)+400, len(want))]) {
+					t.Errorf("ReadFull() after Seek(%d) returned the wrong bytes", sample*4)
+				}
+			}
+
+			// SeekSample seeks to the start of a sample, even from the middle of
+			// one.
+			for _, sample := range []int64{0, 1016, 1500, int64(len(want)/4 - 1)} {
+				if _, err := r.Seek(1, io.SeekStart); err != nil {
+					t.Fatalf("Seek(1) error = %v, want nil", err)
+				}
+				if err := r.SeekSample(sample); err != nil {
+					t.Fatalf("SeekSample(%d) error = %v, want nil", sample, err)
+				}
+				got := make([]byte, 4)
+				if _, err := io.ReadFull(r, got); err != nil {
+					t.Fatalf("ReadFull() after SeekSample(%d) error = %v, want nil", sample, err)
+				}
+				if !bytes.Equal(got, want[sample*4:sample*4+4]) {
+					t.Errorf("ReadFull() after SeekSample(%d) = %v, want %v", sample, got, want[sample*4:sample*4+4])
+				}
+			}
+			if err := r.SeekSample(-1); err == nil {
+				t.Error("SeekSample(-1) error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestNewReader_truncated(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "1BOUN001.SAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReader(bytes.NewReader(bs[:100]), 100); err == nil {
+		t.Error("NewReader() error = nil, want error")
+	}
+}
+
+func TestReaders_noSentinel(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "1BOUN001.SAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The stream ends after two whole blocks, without a sentinel.
+	bs = bs[:blockSize*2]
+
+	if _, err := NewDecoder(bytes.NewReader(bs)).Decode(); err == nil {
+		t.Error("Decode() error = nil, want error")
+	}
+	if _, err := io.ReadAll(NewPCMReader(bytes.NewReader(bs))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("PCMReader: ReadAll() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := NewReader(bytes.NewReader(bs), int64(len(bs))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("NewReader() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestReaders_partialFrame(t *testing.T) {
+	left := make([]int16, 3000)
+	right := make([]int16, 3000)
+	for i := range left {
+		left[i] = int16(i * 7)
+		right[i] = int16(-i * 5)
+	}
+	s, err := FromPCM(left, right)
+	if err != nil {
+		t.Fatalf("FromPCM() error = %v, want nil", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).Encode(s); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+	// Stray bytes after the trailing PCM samples are not a whole frame.
+	bs := append(buf.Bytes(), 0x7F, 0x01, 0x02)
+
+	s, err = NewDecoder(bytes.NewReader(bs)).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	var want []byte
+	for i := range s.LeftBlocks {
+		l, r := s.LeftBlocks[i].AsPCM16Block().Data, s.RightBlocks[i].AsPCM16Block().Data
+		for j := range l {
+			want = binary.LittleEndian.AppendUint16(want, uint16(l[j]))
+			want = binary.LittleEndian.AppendUint16(want, uint16(r[j]))
+		}
+	}
+	if got := len(want) / frameSize; got != len(left) {
+		t.Fatalf("Decode() has %d sample(s), want %d", got, len(left))
+	}
+
+	if err := iotest.TestReader(NewPCMReader(iotest.OneByteReader(bytes.NewReader(bs))), want); err != nil {
+		t.Errorf("PCMReader: %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(bs), int64(len(bs)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v, want nil", err)
+	}
+	if got := r.Size(); got != int64(len(want)) {
+		t.Errorf("Reader.Size() = %d, want %d", got, len(want))
+	}
+	if err := iotest.TestReader(r, want); err != nil {
+		t.Errorf("Reader: %v", err)
+	}
+}
@@ -2,30 +2,150 @@ package sad
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
-	"github.com/jonathaningram/dark-omen/internal/audio"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/internal/readerutil"
+)
+
+const (
+	// sampleRate is the sample rate of the game's audio files, in Hz.
+	sampleRate = 22050
+
+	// adpcmBlockSize is the number of bytes of ADPCM data in each block for
+	// each channel, which holds two samples per byte. The channels are
+	// interleaved every 4 bytes.
+	adpcmBlockSize = 508
+	// sentinelIndex is the index in the header that follows the last ADPCM
+	// block. The remainder of the stream is PCM data.
+	sentinelIndex = 99
 )
 
 type Stream struct {
-	LeftBlocks  []audio.Block
-	RightBlocks []audio.Block
+	LeftBlocks  []adpcm.AnyBlock
+	RightBlocks []adpcm.AnyBlock
 
 	// Note: Storing these so that a re-encoded stream is correct. sample 99 is
-	// always a different value and index 99 is always equal to 99. Not sure if
-	// sample 99 needs to end up in the decoded stream—currently it does not.
+	// always a different value. index 99 is always equal to 99 so it is not
+	// stored. Not sure if sample 99 needs to end up in the decoded
+	// stream—currently it does not.
 
 	leftSample99  int16
-	leftIndex99   int16
 	rightSample99 int16
-	rightIndex99  int16
 }
 
 func (s *Stream) Channels() int {
 	return 2
 }
 
+// SampleRate returns the sample rate of the stream, in Hz.
+func (s *Stream) SampleRate() int {
+	return sampleRate
+}
+
+// NumSamples returns the number of samples in each channel of the stream. It is
+// computed from the size of each block, so no blocks are decoded.
+func (s *Stream) NumSamples() int {
+	var n int
+	for _, b := range s.LeftBlocks {
+		n += b.NumSamples()
+	}
+	return n
+}
+
+// Duration returns the length of the stream when played.
+func (s *Stream) Duration() time.Duration {
+	return duration(s.NumSamples())
+}
+
+// Config holds the format and length of a SAD audio stream.
+type Config struct {
+	SampleRate int
+	Channels   int
+	// NumSamples is the number of samples in each channel.
+	NumSamples int
+}
+
+// Duration returns the length of the stream when played.
+func (c Config) Duration() time.Duration {
+	return duration(c.NumSamples)
+}
+
+func duration(samples int) time.Duration {
+	return time.Duration(samples) * time.Second / sampleRate
+}
+
+// DecodeConfig returns the format and length of the SAD audio stream read from
+// r without decoding its blocks. Only the header of each block is read; if r is
+// an io.Seeker, the rest of each block is seeked past rather than read.
+func DecodeConfig(r io.Reader) (Config, error) {
+	c := Config{
+		SampleRate: sampleRate,
+		Channels:   2,
+	}
+
+	for {
+		var bs [8]byte
+		if _, err := io.ReadFull(r, bs[:]); err != nil {
+			return Config{}, fmt.Errorf("could not read stereo sample and index data: %w", err)
+		}
+		leftIndex := int16(binary.LittleEndian.Uint16(bs[2:4]))
+		rightIndex := int16(binary.LittleEndian.Uint16(bs[6:8]))
+
+		if leftIndex == sentinelIndex && rightIndex == sentinelIndex {
+			break
+		}
+
+		if err := readerutil.Skip(r, adpcmBlockSize*2); err != nil {
+			return Config{}, fmt.Errorf("could not read stereo ADPCM data: %w", err)
+		}
+		c.NumSamples += adpcmBlockSize * 2
+	}
+
+	n, err := readerutil.Remaining(r)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read PCM data: %w", err)
+	}
+	c.NumSamples += int(n / 4)
+
+	return c, nil
+}
+
+// FromPCM returns a new stream that encodes the left and right 16-bit PCM
+// samples as IMA ADPCM blocks. Samples that do not fill a whole block are
+// stored as PCM at the end of the stream, as in the game's audio files. left
+// and right must have the same number of samples.
+func FromPCM(left, right []int16) (*Stream, error) {
+	if len(left) != len(right) {
+		return nil, fmt.Errorf("left channel has %d sample(s), right channel has %d", len(left), len(right))
+	}
+
+	n := len(left)/(adpcmBlockSize*2) + 1
+	s := &Stream{
+		LeftBlocks:  make([]adpcm.AnyBlock, 0, n),
+		RightBlocks: make([]adpcm.AnyBlock, 0, n),
+	}
+
+	leftEnc := &adpcm.Encoder{}
+	rightEnc := &adpcm.Encoder{}
+	for len(left) >= adpcmBlockSize*2 {
+		s.LeftBlocks = append(s.LeftBlocks, leftEnc.EncodeBlock(left[:adpcmBlockSize*2]))
+		s.RightBlocks = append(s.RightBlocks, rightEnc.EncodeBlock(right[:adpcmBlockSize*2]))
+		left = left[adpcmBlockSize*2:]
+		right = right[adpcmBlockSize*2:]
+	}
+
+	s.leftSample99 = leftEnc.Sample()
+	s.rightSample99 = rightEnc.Sample()
+	s.LeftBlocks = append(s.LeftBlocks, adpcm.NewPCM16BlockFromInt16Slice(append([]int16(nil), left...)))
+	s.RightBlocks = append(s.RightBlocks, adpcm.NewPCM16BlockFromInt16Slice(append([]int16(nil), right...)))
+
+	return s, nil
+}
+
 // Decoder reads and decodes a SAD audio stream from an input stream.
 type Decoder struct {
 	r io.Reader
@@ -40,8 +160,8 @@ func NewDecoder(r io.Reader) *Decoder {
 // new stream containing decoded left and right blocks.
 func (d *Decoder) Decode() (*Stream, error) {
 	s := &Stream{
-		LeftBlocks:  make([]audio.Block, 0),
-		RightBlocks: make([]audio.Block, 0),
+		LeftBlocks:  make([]adpcm.AnyBlock, 0),
+		RightBlocks: make([]adpcm.AnyBlock, 0),
 	}
 
 	for {
@@ -58,15 +178,13 @@ func (d *Decoder) Decode() (*Stream, error) {
 		rightSample := int16(binary.LittleEndian.Uint16(bs[4:6]))
 		rightIndex := int16(binary.LittleEndian.Uint16(bs[6:8]))
 
-		if leftIndex == 99 && rightIndex == 99 {
+		if leftIndex == sentinelIndex && rightIndex == sentinelIndex {
 			s.leftSample99 = leftSample
-			s.leftIndex99 = leftIndex
 			s.rightSample99 = rightSample
-			s.rightIndex99 = rightIndex
 			break
 		}
 
-		const size = 1016
+		const size = adpcmBlockSize * 2
 		buf := make([]byte, size)
 		n, err = d.r.Read(buf)
 		if n != size {
@@ -88,8 +206,8 @@ func (d *Decoder) Decode() (*Stream, error) {
 			}
 		}
 
-		s.LeftBlocks = append(s.LeftBlocks, audio.NewADPCMBlock(leftSample, leftIndex, leftData))
-		s.RightBlocks = append(s.RightBlocks, audio.NewADPCMBlock(rightSample, rightIndex, rightData))
+		s.LeftBlocks = append(s.LeftBlocks, adpcm.NewBlock(leftSample, leftIndex, leftData))
+		s.RightBlocks = append(s.RightBlocks, adpcm.NewBlock(rightSample, rightIndex, rightData))
 	}
 
 	// Read remaining bytes.
@@ -108,8 +226,8 @@ func (d *Decoder) Decode() (*Stream, error) {
 		rightBuf[i] = rightSample
 	}
 
-	s.LeftBlocks = append(s.LeftBlocks, audio.NewPCM16BlockFromInt16Slice(leftBuf))
-	s.RightBlocks = append(s.RightBlocks, audio.NewPCM16BlockFromInt16Slice(rightBuf))
+	s.LeftBlocks = append(s.LeftBlocks, adpcm.NewPCM16BlockFromInt16Slice(leftBuf))
+	s.RightBlocks = append(s.RightBlocks, adpcm.NewPCM16BlockFromInt16Slice(rightBuf))
 
 	return s, nil
 }
@@ -124,90 +242,113 @@ func NewEncoder(w io.Writer) *Encoder {
 	return &Encoder{w: w}
 }
 
-// Encode writes the encoded SAD audio stream to its output.
+// Encode writes the encoded SAD audio stream to its output. The stream is
+// validated before anything is written: the left and right channels must have
+// the same number of blocks, every block but the last must be an ADPCM block of
+// 508 bytes, and the last blocks of each channel must have the same number of
+// samples. If the last blocks are ADPCM blocks, the stream is written without
+// any trailing PCM samples.
 func (e *Encoder) Encode(s *Stream) error {
-	for i := 0; i < len(s.LeftBlocks)-1; i++ {
-		leftBlock, ok := s.LeftBlocks[i].(*audio.ADPCMBlock)
-		if !ok {
-			return fmt.Errorf("left block at position %d is not an ADPCM block", i)
-		}
-		if err := binary.Write(e.w, binary.LittleEndian, leftBlock.Sample()); err != nil {
-			return err
-		}
-		if err := binary.Write(e.w, binary.LittleEndian, leftBlock.Index()); err != nil {
-			return err
-		}
-		rightBlock, ok := s.RightBlocks[i].(*audio.ADPCMBlock)
-		if !ok {
-			return fmt.Errorf("right block at position %d is not an ADPCM block", i)
-		}
-		if err := binary.Write(e.w, binary.LittleEndian, rightBlock.Sample()); err != nil {
-			return err
-		}
-		if err := binary.Write(e.w, binary.LittleEndian, rightBlock.Index()); err != nil {
-			return err
-		}
+	if err := s.validate(); err != nil {
+		return err
+	}
 
-		leftData, err := leftBlock.Bytes()
-		if err != nil {
-			return err
-		}
-		rightData, err := rightBlock.Bytes()
-		if err != nil {
-			return err
+	adpcmBlocks := len(s.LeftBlocks) - 1
+	_, lastIsADPCM := s.LeftBlocks[adpcmBlocks].(*adpcm.Block)
+	if lastIsADPCM {
+		adpcmBlocks++
+	}
+
+	buf := make([]byte, blockSize)
+	for i := 0; i < adpcmBlocks; i++ {
+		left := s.LeftBlocks[i].(*adpcm.Block)
+		right := s.RightBlocks[i].(*adpcm.Block)
+		leftData, _ := left.Bytes()
+		rightData, _ := right.Bytes()
+
+		binary.LittleEndian.PutUint16(buf[0:2], uint16(left.Sample()))
+		binary.LittleEndian.PutUint16(buf[2:4], uint16(left.Index()))
+		binary.LittleEndian.PutUint16(buf[4:6], uint16(right.Sample()))
+		binary.LittleEndian.PutUint16(buf[6:8], uint16(right.Index()))
+
+		// The channels are interleaved every 4 bytes.
+		data := buf[8:]
+		for j := 0; j < adpcmBlockSize/4; j++ {
+			copy(data[j*8:j*8+4], leftData[j*4:j*4+4])
+			copy(data[j*8+4:j*8+8], rightData[j*4:j*4+4])
 		}
 
-		for j := 0; j < len(leftData); j += 4 {
-			for k := 0; k < 4; k++ {
-				n, err := e.w.Write([]byte{leftData[j+k]})
-				if n != 1 {
-					return fmt.Errorf("wrote %d byte(s), expected %d", n, 1)
-				}
-				if err != nil {
-					return err
-				}
-			}
-			for k := 0; k < 4; k++ {
-				n, err := e.w.Write([]byte{rightData[j+k]})
-				if n != 1 {
-					return fmt.Errorf("wrote %d byte(s), expected %d", n, 1)
-				}
-				if err != nil {
-					return err
-				}
-			}
+		if _, err := e.w.Write(buf); err != nil {
+			return fmt.Errorf("could not write block %d: %w", i, err)
 		}
 	}
 
-	if err := binary.Write(e.w, binary.LittleEndian, s.leftSample99); err != nil {
-		return err
+	var left, right []int16
+	if !lastIsADPCM {
+		left = s.LeftBlocks[adpcmBlocks].AsPCM16Block().Data
+		right = s.RightBlocks[adpcmBlocks].AsPCM16Block().Data
 	}
-	if err := binary.Write(e.w, binary.LittleEndian, s.leftIndex99); err != nil {
-		return err
-	}
-	if err := binary.Write(e.w, binary.LittleEndian, s.rightSample99); err != nil {
-		return err
+
+	buf = buf[:0]
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(s.leftSample99))
+	buf = binary.LittleEndian.AppendUint16(buf, sentinelIndex)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(s.rightSample99))
+	buf = binary.LittleEndian.AppendUint16(buf, sentinelIndex)
+	for j := range left {
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(left[j]))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(right[j]))
 	}
-	if err := binary.Write(e.w, binary.LittleEndian, s.rightIndex99); err != nil {
-		return err
+
+	if _, err := e.w.Write(buf); err != nil {
+		return fmt.Errorf("could not write PCM data: %w", err)
 	}
 
-	leftBytes, err := s.LeftBlocks[len(s.LeftBlocks)-1].Bytes()
-	if err != nil {
-		return err
+	return nil
+}
+
+// validate returns an error describing the first problem with s that would
+// stop it from being encoded.
+func (s *Stream) validate() error {
+	if l, r := len(s.LeftBlocks), len(s.RightBlocks); l != r {
+		return fmt.Errorf("left channel has %d block(s), right channel has %d", l, r)
 	}
-	rightBytes, err := s.RightBlocks[len(s.LeftBlocks)-1].Bytes()
-	if err != nil {
-		return err
+	if len(s.LeftBlocks) == 0 {
+		return errors.New("stream has no blocks")
 	}
-	for i := 0; i < len(leftBytes); i += 2 {
-		if err := binary.Write(e.w, binary.LittleEndian, []byte{leftBytes[i], leftBytes[i+1]}); err != nil {
-			return err
-		}
-		if err := binary.Write(e.w, binary.LittleEndian, []byte{rightBytes[i], rightBytes[i+1]}); err != nil {
-			return err
+
+	last := len(s.LeftBlocks) - 1
+	for i := range s.LeftBlocks {
+		for _, ch := range []struct {
+			name  string
+			block adpcm.AnyBlock
+		}{
+			{name: "left", block: s.LeftBlocks[i]},
+			{name: "right", block: s.RightBlocks[i]},
+		} {
+			if ch.block == nil {
+				return fmt.Errorf("%s block at position %d is nil", ch.name, i)
+			}
+			b, ok := ch.block.(*adpcm.Block)
+			if !ok {
+				if i == last {
+					continue
+				}
+				return fmt.Errorf("%s block at position %d is not an ADPCM block", ch.name, i)
+			}
+			if data, _ := b.Bytes(); len(data) != adpcmBlockSize {
+				return fmt.Errorf("%s block at position %d has %d byte(s) of ADPCM data, expected %d", ch.name, i, len(data), adpcmBlockSize)
+			}
 		}
 	}
 
+	_, leftIsADPCM := s.LeftBlocks[last].(*adpcm.Block)
+	_, rightIsADPCM := s.RightBlocks[last].(*adpcm.Block)
+	if leftIsADPCM != rightIsADPCM {
+		return fmt.Errorf("only one of the blocks at position %d is an ADPCM block", last)
+	}
+	if l, r := s.LeftBlocks[last].NumSamples(), s.RightBlocks[last].NumSamples(); l != r {
+		return fmt.Errorf("left block at position %d has %d sample(s), right block has %d", last, l, r)
+	}
+
 	return nil
 }
@@ -3,11 +3,19 @@ package sad
 import (
 	"bytes"
 	"io"
+	"math"
 	"os"
 	"path"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-audio/wav"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+	"github.com/mewkiz/flac"
 )
 
 var stream *Stream
@@ -73,6 +81,140 @@ func TestRoundTrip(t *testing.T) {
 	}
 }
 
+// writeCounter counts the calls to Write, which are system calls when
+// encoding to an unbuffered file.
+type writeCounter struct {
+	writes int
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func BenchmarkEncode(b *testing.B) {
+	tests := []string{
+		"1BOUN001.SAD",
+		"1CHAS001.SAD",
+	}
+	for _, tt := range tests {
+		b.Run(tt, func(b *testing.B) {
+			b.ReportAllocs()
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				b.Fatal(err)
+			}
+			s, err := NewDecoder(bytes.NewReader(bs)).Decode()
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(int64(len(bs)))
+			w := &writeCounter{}
+			for n := 0; n < b.N; n++ {
+				if err := NewEncoder(w).Encode(s); err != nil {
+					b.Fatalf("Encode() error = %v, want nil", err)
+				}
+			}
+			b.ReportMetric(float64(w.writes)/float64(b.N), "writes/op")
+		})
+	}
+}
+
+func TestEncode_invalid(t *testing.T) {
+	block := func() adpcm.AnyBlock {
+		return adpcm.NewBlock(0, 0, make([]byte, 508))
+	}
+	pcm := func(n int) adpcm.AnyBlock {
+		return adpcm.NewPCM16BlockFromInt16Slice(make([]int16, n))
+	}
+
+	tests := []struct {
+		name   string
+		stream *Stream
+	}{
+		{
+			name:   "no blocks",
+			stream: &Stream{},
+		},
+		{
+			name: "mismatched block counts",
+			stream: &Stream{
+				LeftBlocks:  []adpcm.AnyBlock{block(), pcm(1)},
+				RightBlocks: []adpcm.AnyBlock{pcm(1)},
+			},
+		},
+		{
+			name: "PCM block before the last block",
+			stream: &Stream{
+				LeftBlocks:  []adpcm.AnyBlock{pcm(1), pcm(1)},
+				RightBlocks: []adpcm.AnyBlock{block(), pcm(1)},
+			},
+		},
+		{
+			name: "short ADPCM block",
+			stream: &Stream{
+				LeftBlocks:  []adpcm.AnyBlock{block(), pcm(1)},
+				RightBlocks: []adpcm.AnyBlock{adpcm.NewBlock(0, 0, make([]byte, 4)), pcm(1)},
+			},
+		},
+		{
+			name: "nil block",
+			stream: &Stream{
+				LeftBlocks:  []adpcm.AnyBlock{nil, pcm(1)},
+				RightBlocks: []adpcm.AnyBlock{block(), pcm(1)},
+			},
+		},
+		{
+			name: "mismatched PCM sample counts",
+			stream: &Stream{
+				LeftBlocks:  []adpcm.AnyBlock{block(), pcm(1)},
+				RightBlocks: []adpcm.AnyBlock{block(), pcm(2)},
+			},
+		},
+		{
+			name: "mismatched last block types",
+			stream: &Stream{
+				LeftBlocks:  []adpcm.AnyBlock{block()},
+				RightBlocks: []adpcm.AnyBlock{pcm(1016)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writeCounter{}
+			if err := NewEncoder(w).Encode(tt.stream); err == nil {
+				t.Error("Encode() error = nil, want error")
+			}
+			if w.writes != 0 {
+				t.Errorf("Encode() wrote %d time(s), want nothing written", w.writes)
+			}
+		})
+	}
+}
+
+func TestEncode_noTrailingPCM(t *testing.T) {
+	s := &Stream{
+		LeftBlocks:  []adpcm.AnyBlock{adpcm.NewBlock(1, 2, make([]byte, 508))},
+		RightBlocks: []adpcm.AnyBlock{adpcm.NewBlock(3, 4, make([]byte, 508))},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).Encode(s); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+	if got, want := buf.Len(), 1024+8; got != want {
+		t.Errorf("Encode() wrote %d byte(s), want %d", got, want)
+	}
+
+	got, err := NewDecoder(buf).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+	if n := got.NumSamples(); n != 1016 {
+		t.Errorf("decoded %d sample(s), want %d", n, 1016)
+	}
+}
+
 func BenchmarkEncodeToWAV(b *testing.B) {
 	tests := []string{
 		"1BOUN001.SAD",
@@ -151,9 +293,279 @@ func TestEncodeToWAV(t *testing.T) {
 	}
 }
 
+func TestEncodeToFLAC(t *testing.T) {
+	tests := []string{
+		"1BOUN001.SAD",
+		"1CHAS001.SAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			f, err := os.Open(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			stream, err := NewDecoder(f).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			var buf bytes.Buffer
+			if err := stream.EncodeToFLAC(&buf); err != nil {
+				t.Fatalf("EncodeToFLAC() error = %v, want nil", err)
+			}
+
+			fs, err := flac.New(&buf)
+			if err != nil {
+				t.Fatalf("flac.New() error = %v, want nil", err)
+			}
+			if fs.Info.SampleRate != 22050 || fs.Info.NChannels != 2 || fs.Info.BitsPerSample != 16 {
+				t.Errorf("got FLAC stream info = %+v, want 22050 Hz 16-bit stereo", fs.Info)
+			}
+			got := make([][]int16, 2)
+			for {
+				frame, err := fs.ParseNext()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ParseNext() error = %v, want nil", err)
+				}
+				for c, sf := range frame.Subframes {
+					for _, v := range sf.Samples {
+						got[c] = append(got[c], int16(v))
+					}
+				}
+			}
+
+			// FLAC is lossless, so the samples must match the decoded stream
+			// exactly.
+			want := [][]int16{
+				adpcm.AppendSamples(nil, stream.LeftBlocks),
+				adpcm.AppendSamples(nil, stream.RightBlocks),
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("FLAC samples mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func truncateBytes(bs []byte, size int) []byte {
 	if len(bs) > size {
 		return bs[:size]
 	}
 	return bs
 }
+
+func TestFromPCM(t *testing.T) {
+	// A 440 Hz tone on the left and a 660 Hz tone on the right that fill
+	// several blocks and leave a partial block of PCM samples at the end.
+	left := audiotest.Tone(sampleRate, 1016*4+123, 440, 8000)
+	right := audiotest.Tone(sampleRate, len(left), 660, 4000)
+
+	s, err := FromPCM(left, right)
+	if err != nil {
+		t.Fatalf("FromPCM() error = %v, want nil", err)
+	}
+
+	encoded := &bytes.Buffer{}
+	if err := NewEncoder(encoded).Encode(s); err != nil {
+		t.Fatalf("Encode() error = %v, want nil", err)
+	}
+
+	stream, err := NewDecoder(encoded).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v, want nil", err)
+	}
+
+	for _, ch := range []struct {
+		name   string
+		want   []int16
+		blocks []adpcm.AnyBlock
+	}{
+		{name: "left", want: left, blocks: stream.LeftBlocks},
+		{name: "right", want: right, blocks: stream.RightBlocks},
+	} {
+		var got []int16
+		for _, b := range ch.blocks {
+			got = append(got, b.AsPCM16Block().Data...)
+		}
+		if len(got) != len(ch.want) {
+			t.Fatalf("decoded %d %s sample(s), want %d", len(got), ch.name, len(ch.want))
+		}
+
+		// IMA ADPCM should give a signal-to-noise ratio of better than 30 dB
+		// for a pure tone once the step index has adapted to it.
+		if snr := audiotest.SignalToNoise(ch.want[audiotest.Warmup:], got[audiotest.Warmup:]); snr < 30 {
+			t.Errorf("%s signal-to-noise ratio = %.1f dB, want at least 30 dB", ch.name, snr)
+		}
+
+		// The trailing PCM samples are stored exactly.
+		if diff := cmp.Diff(ch.want[1016*4:], got[1016*4:]); diff != "" {
+			t.Errorf("%s PCM samples mismatch (-want +got):\n%s", ch.name, diff)
+		}
+	}
+}
+
+func TestFromPCM_mismatchedChannels(t *testing.T) {
+	if _, err := FromPCM(make([]int16, 2), make([]int16, 3)); err == nil {
+		t.Error("FromPCM() error = nil, want error")
+	}
+}
+
+func TestDecodeWAV(t *testing.T) {
+	tests := []string{
+		"1BOUN001.WAV",
+		"1CHAS001.WAV",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			f, err := os.Open(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			want, err := wav.NewDecoder(f).FullPCMBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := DecodeWAV(f)
+			if err != nil {
+				t.Fatalf("DecodeWAV() error = %v, want nil", err)
+			}
+
+			encoded := &bytes.Buffer{}
+			if err := NewEncoder(encoded).Encode(s); err != nil {
+				t.Fatalf("Encode() error = %v, want nil", err)
+			}
+			stream, err := NewDecoder(encoded).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			var got []int16
+			for i := range stream.LeftBlocks {
+				left := stream.LeftBlocks[i].AsPCM16Block().Data
+				right := stream.RightBlocks[i].AsPCM16Block().Data
+				for j := range left {
+					got = append(got, left[j], right[j])
+				}
+			}
+			if len(got) != len(want.Data) {
+				t.Fatalf("decoded %d sample(s), want %d", len(got), len(want.Data))
+			}
+
+			var signal, noise float64
+			for i, v := range want.Data {
+				s := float64(v)
+				n := float64(got[i]) - s
+				signal += s * s
+				noise += n * n
+			}
+			if snr := 10 * math.Log10(signal/noise); snr < 20 {
+				t.Errorf("signal-to-noise ratio = %.1f dB, want at least 20 dB", snr)
+			}
+		})
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []string{
+		"1BOUN001.SAD",
+		"1CHAS001.SAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			bs, err := os.ReadFile(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := NewDecoder(bytes.NewReader(bs)).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+			var decoded int
+			for _, b := range s.LeftBlocks {
+				decoded += len(b.AsPCM16Block().Data)
+			}
+			if got := s.NumSamples(); got != decoded {
+				t.Errorf("Stream.NumSamples() = %d, want %d", got, decoded)
+			}
+
+			want := Config{SampleRate: 22050, Channels: 2, NumSamples: decoded}
+
+			// Probe both a reader that can seek past blocks and one that
+			// cannot.
+			for _, r := range []io.Reader{bytes.NewReader(bs), bytes.NewBuffer(bs)} {
+				got, err := DecodeConfig(r)
+				if err != nil {
+					t.Fatalf("DecodeConfig() error = %v, want nil", err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("DecodeConfig() mismatch (-want +got):\n%s", diff)
+				}
+			}
+
+			if got, want := s.Duration(), time.Duration(decoded)*time.Second/22050; got != want {
+				t.Errorf("Stream.Duration() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeConfig_truncated(t *testing.T) {
+	bs, err := os.ReadFile(path.Join("testdata", "1BOUN001.SAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeConfig(bytes.NewReader(bs[:100])); err == nil {
+		t.Error("DecodeConfig() error = nil, want error")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []string{
+		"1BOUN001.SAD",
+		"1CHAS001.SAD",
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			f, err := os.Open(path.Join("testdata", tt))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			stream, err := NewDecoder(f).Decode()
+			if err != nil {
+				t.Fatalf("Decode() error = %v, want nil", err)
+			}
+
+			const target = -23
+			normalized, err := stream.Normalize(target)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v, want nil", err)
+			}
+			if got, want := normalized.NumSamples(), stream.NumSamples(); got != want {
+				t.Errorf("Normalize() has %d sample(s), want %d", got, want)
+			}
+
+			m, err := normalized.Loudness()
+			if err != nil {
+				t.Fatalf("Loudness() error = %v, want nil", err)
+			}
+			// Re-encoding as ADPCM adds a little noise.
+			if d := math.Abs(m.Integrated - target); d > 0.5 {
+				t.Errorf("Normalize() loudness = %.2f LUFS, want %.2f LUFS", m.Integrated, float64(target))
+			}
+		})
+	}
+}
@@ -3,26 +3,15 @@ package sad
 import (
 	"io"
 
-	"github.com/go-audio/audio"
 	"github.com/go-audio/wav"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+	internalaudio "github.com/jonathaningram/dark-omen/internal/audio"
 )
 
 func (s *Stream) EncodeToWAV(w io.WriteSeeker) error {
-	const sampleRate = 22050
-
-	buf := &audio.IntBuffer{
-		Format: &audio.Format{
-			NumChannels: s.Channels(),
-			SampleRate:  sampleRate,
-		},
-	}
-	for i := 0; i < len(s.LeftBlocks); i++ {
-		leftPCM16Block := s.LeftBlocks[i].AsPCM16Block()
-		rightPCM16Block := s.RightBlocks[i].AsPCM16Block()
-
-		for j := 0; j < len(leftPCM16Block.Data); j++ {
-			buf.Data = append(buf.Data, int(leftPCM16Block.Data[j]), int(rightPCM16Block.Data[j]))
-		}
+	buf, err := adpcm.ToIntBuffer(sampleRate, s.LeftBlocks, s.RightBlocks)
+	if err != nil {
+		return err
 	}
 	enc := wav.NewEncoder(
 		w,
@@ -39,3 +28,14 @@ func (s *Stream) EncodeToWAV(w io.WriteSeeker) error {
 	}
 	return nil
 }
+
+// DecodeWAV reads the WAV file from r and returns a new stream that encodes it.
+// The audio is resampled to 22050 Hz, mixed to stereo and dithered to 16 bits
+// if needed.
+func DecodeWAV(r io.ReadSeeker) (*Stream, error) {
+	samples, err := internalaudio.ReadWAV(r, sampleRate, 2)
+	if err != nil {
+		return nil, err
+	}
+	return FromPCM(samples[0], samples[1])
+}
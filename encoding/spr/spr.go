@@ -25,7 +25,8 @@ const (
 
 // Decoder reads and decodes a sprite from an input stream.
 type Decoder struct {
-	r io.ReaderAt
+	r    io.ReaderAt
+	opts DecoderOptions
 }
 
 // NewDecoder returns a new decoder that reads from r.
@@ -33,6 +34,12 @@ func NewDecoder(r io.ReaderAt) *Decoder {
 	return &Decoder{r: r}
 }
 
+// NewDecoderWithOptions returns a new decoder that reads from r and is
+// configured by opts.
+func NewDecoderWithOptions(r io.ReaderAt, opts DecoderOptions) *Decoder {
+	return &Decoder{r: r, opts: opts}
+}
+
 // Decode reads the encoded sprite information from its input and returns a new
 // Sprite containing decoded information and frames.
 func (d *Decoder) Decode() (*Sprite, error) {
@@ -175,17 +182,11 @@ func (d *Decoder) readColorTable(header *header) ([]color.RGBA, error) {
 		entry := colorTable[4*i : 4*(i+1)]
 
 		// byte 4 (index 3) is not used
-		b, g, r, a := entry[0], entry[1], entry[2], uint8(255)
-
-		if b < 8 && g < 8 && r < 8 {
-			a = 0
-		}
-
 		colors[i] = color.RGBA{
 			B: entry[0],
 			G: entry[1],
 			R: entry[2],
-			A: a,
+			A: 255,
 		}
 	}
 
@@ -222,11 +223,22 @@ func (d *Decoder) readFrameData(header *header, frameHeaders []*frameHeader, col
 
 		img := image.NewNRGBA(image.Rect(0, 0, info.width, info.height))
 
+		var palette [256]*color.NRGBA
 		var x int
 		var y int
 
 		for _, b := range raw {
-			img.Set(x, y, colors[info.colorTableOffset+int(b)])
+			c := palette[b]
+			if c == nil {
+				ci := info.colorTableOffset + int(b)
+				if ci >= len(colors) {
+					return nil, fmt.Errorf("frame %d references color %d, but the color table only has %d entries", i, ci, len(colors))
+				}
+				nrgba := d.opts.color(b, colors[ci])
+				c = &nrgba
+				palette[b] = c
+			}
+			img.SetNRGBA(x, y, *c)
 
 			if x == img.Rect.Max.X-1 {
 				x = 0
@@ -0,0 +1,124 @@
+package spr
+
+import "image/color"
+
+// DecoderOptions configures how a Decoder converts a sprite's color table into
+// image colors.
+type DecoderOptions struct {
+	// Transparency decides which color table entries are fully transparent.
+	// If nil, the policy returned by DefaultTransparency is used.
+	Transparency Transparency
+	// Translucent lists color table entries that are drawn partially
+	// transparent. Entries are matched on their red, green and blue
+	// components and take precedence over Transparency.
+	//
+	// TODO: The color table entries the game draws as shadows or at half
+	// transparency have not been identified in the sprite data, so none are
+	// translucent by default. Shadow and HalfTransparent build entries for
+	// colors chosen by the caller.
+	Translucent []Translucency
+}
+
+// DefaultTransparency returns the transparency policy used when none is given.
+// It matches the behavior of earlier versions of this package.
+func DefaultTransparency() Transparency {
+	return ThresholdTransparency{Threshold: 8}
+}
+
+// Transparency is a policy that decides whether a color in a frame is fully
+// transparent.
+type Transparency interface {
+	// Transparent reports whether the color c, found at index i of the
+	// frame's palette, is fully transparent.
+	Transparent(i uint8, c color.RGBA) bool
+}
+
+// ThresholdTransparency treats a color as transparent when its red, green and
+// blue components are all below Threshold.
+type ThresholdTransparency struct {
+	Threshold uint8
+}
+
+// Transparent implements Transparency.
+func (t ThresholdTransparency) Transparent(_ uint8, c color.RGBA) bool {
+	return c.R < t.Threshold && c.G < t.Threshold && c.B < t.Threshold
+}
+
+// ColorKeyTransparency treats a color as transparent when its red, green and
+// blue components exactly match those of Key.
+type ColorKeyTransparency struct {
+	Key color.RGBA
+}
+
+// Transparent implements Transparency.
+func (t ColorKeyTransparency) Transparent(_ uint8, c color.RGBA) bool {
+	return sameRGB(c, t.Key)
+}
+
+// IndexZeroTransparency treats the first entry of each frame's palette as
+// transparent, regardless of its color.
+type IndexZeroTransparency struct{}
+
+// Transparent implements Transparency.
+func (IndexZeroTransparency) Transparent(i uint8, _ color.RGBA) bool {
+	return i == 0
+}
+
+// NoTransparency treats every color as opaque.
+type NoTransparency struct{}
+
+// Transparent implements Transparency.
+func (NoTransparency) Transparent(uint8, color.RGBA) bool {
+	return false
+}
+
+// A Translucency replaces a color table entry with a partially transparent
+// color.
+type Translucency struct {
+	// Color is the color table entry to match. Its alpha is ignored.
+	Color color.RGBA
+	// Output is the color emitted in place of Color.
+	Output color.NRGBA
+}
+
+// Shadow returns a Translucency that draws c as black with the given alpha, so
+// that it darkens whatever the sprite is drawn over.
+func Shadow(c color.RGBA, alpha uint8) Translucency {
+	return Translucency{
+		Color:  c,
+		Output: color.NRGBA{A: alpha},
+	}
+}
+
+// HalfTransparent returns a Translucency that draws c in its own color at 50%
+// opacity.
+func HalfTransparent(c color.RGBA) Translucency {
+	return Translucency{
+		Color:  c,
+		Output: color.NRGBA{R: c.R, G: c.G, B: c.B, A: 128},
+	}
+}
+
+// color returns the image color for the color table entry c found at index i
+// of a frame's palette.
+func (o *DecoderOptions) color(i uint8, c color.RGBA) color.NRGBA {
+	for _, t := range o.Translucent {
+		if sameRGB(c, t.Color) {
+			return t.Output
+		}
+	}
+
+	transparency := o.Transparency
+	if transparency == nil {
+		transparency = DefaultTransparency()
+	}
+	if transparency.Transparent(i, c) {
+		return color.NRGBA{}
+	}
+
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}
+}
+
+func sameRGB(a, b color.RGBA) bool {
+	return a.R == b.R && a.G == b.G && a.B == b.B
+}
@@ -0,0 +1,132 @@
+package spr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testSprite returns a sprite with a single uncompressed 2x2 frame whose
+// pixels are the given colors, in order.
+func testSprite(colors ...color.RGBA) []byte {
+	const (
+		frameHeaderOffset = headerSize
+		frameDataOffset   = frameHeaderOffset + frameHeaderSize
+	)
+	colorTableOffset := frameDataOffset + len(colors)
+
+	buf := &bytes.Buffer{}
+	u32 := func(v int) {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32(v))
+		buf.Write(b)
+	}
+
+	buf.WriteString(format)
+	u32(colorTableOffset + 4*len(colors)) // file size
+	u32(frameHeaderOffset)
+	u32(frameDataOffset)
+	u32(colorTableOffset)
+	u32(len(colors)) // color table entries
+	u32(1)           // palette count
+	u32(1)           // frame count
+
+	buf.WriteByte(byte(FrameTypeNormal))
+	buf.WriteByte(byte(compressionTypeNone))
+	buf.Write([]byte{byte(len(colors)), 0})
+	buf.Write([]byte{0, 0, 0, 0}) // x, y
+	buf.Write([]byte{2, 0, 2, 0}) // width, height
+	u32(0)                        // data offset
+	u32(len(colors))              // compressed size
+	u32(len(colors))              // uncompressed size
+	u32(0)                        // color table offset
+	u32(0)                        // unused
+
+	for i := range colors {
+		buf.WriteByte(byte(i))
+	}
+	for _, c := range colors {
+		buf.Write([]byte{c.B, c.G, c.R, 0})
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecoderOptions(t *testing.T) {
+	var (
+		black   = color.RGBA{A: 255}
+		dark    = color.RGBA{R: 5, G: 5, B: 5, A: 255}
+		magenta = color.RGBA{R: 255, B: 255, A: 255}
+		blue    = color.RGBA{R: 10, G: 20, B: 200, A: 255}
+	)
+	data := testSprite(blue, dark, magenta, black)
+
+	opaque := func(c color.RGBA) color.NRGBA {
+		return color.NRGBA{R: c.R, G: c.G, B: c.B, A: 255}
+	}
+
+	tests := []struct {
+		name string
+		opts DecoderOptions
+		want []color.NRGBA
+	}{
+		{
+			name: "default",
+			want: []color.NRGBA{opaque(blue), {}, opaque(magenta), {}},
+		},
+		{
+			name: "no transparency",
+			opts: DecoderOptions{Transparency: NoTransparency{}},
+			want: []color.NRGBA{opaque(blue), opaque(dark), opaque(magenta), opaque(black)},
+		},
+		{
+			name: "index zero",
+			opts: DecoderOptions{Transparency: IndexZeroTransparency{}},
+			want: []color.NRGBA{{}, opaque(dark), opaque(magenta), opaque(black)},
+		},
+		{
+			name: "color key",
+			opts: DecoderOptions{Transparency: ColorKeyTransparency{Key: magenta}},
+			want: []color.NRGBA{opaque(blue), opaque(dark), {}, opaque(black)},
+		},
+		{
+			name: "translucent",
+			opts: DecoderOptions{
+				Transparency: NoTransparency{},
+				Translucent: []Translucency{
+					Shadow(magenta, 96),
+					HalfTransparent(blue),
+				},
+			},
+			want: []color.NRGBA{
+				{R: 10, G: 20, B: 200, A: 128},
+				opaque(dark),
+				{A: 96},
+				opaque(black),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoderWithOptions(bytes.NewReader(data), tt.opts)
+			sprite, err := d.Decode()
+			if err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			img := sprite.Frames[0].Image
+
+			var got []color.NRGBA
+			for y := 0; y < 2; y++ {
+				for x := 0; x < 2; x++ {
+					got = append(got, img.NRGBAAt(x, y))
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Decoder.Decode() pixels mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
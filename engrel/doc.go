@@ -1,3 +1,8 @@
 // Package engrel provides functions that can access information found in Dark
 // Omen's PRG_ENG/ENGREL.exe executable file.
+//
+// The tables in the executable are at different offsets in each localized,
+// patched or differently linked release. Open locates the tables by searching
+// the executable's data sections and returns an Executable that reads them.
+// The Read functions assume the offsets of the English release.
 package engrel
@@ -0,0 +1,172 @@
+package engrel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrUnknownExecutable is returned by Open when none of the tables of
+// ENGREL.EXE are found in the executable.
+var ErrUnknownExecutable = errors.New("unknown ENGREL.EXE release")
+
+// Language is the language of a release of Dark Omen.
+type Language int
+
+const (
+	// UnknownLanguage is the language of a release whose magic item names
+	// table was not found.
+	UnknownLanguage Language = iota
+	// English is the language of the English release.
+	English
+	// French is the language of the French release.
+	French
+	// German is the language of the German release.
+	German
+)
+
+func (l Language) String() string {
+	switch l {
+	case English:
+		return "English"
+	case French:
+		return "French"
+	case German:
+		return "German"
+	default:
+		return "unknown"
+	}
+}
+
+// layout is where the tables are found in a release of ENGREL.EXE.
+type layout struct {
+	language             Language
+	spriteNamesOffset    int64
+	magicItemNamesOffset int64
+	// firstMagicItemName is the first name in the magic item names table. The
+	// names are localized, so it identifies the release.
+	firstMagicItemName string
+}
+
+// layouts are the known releases of ENGREL.EXE.
+//
+// Only the English release has been mapped. The offsets of the French and
+// German releases and the first names in their magic item names tables have not
+// been mapped yet, so Open reports those releases as UnknownLanguage.
+var layouts = []layout{
+	{
+		language:             English,
+		spriteNamesOffset:    spriteNamesStartOffset,
+		magicItemNamesOffset: magicItemNamesStartOffset,
+		firstMagicItemName:   "SingleSanguine",
+	},
+}
+
+// matches reports whether the executable in r has the layout.
+func (l *layout) matches(r io.ReaderAt) bool {
+	// The table may be preceded by padding.
+	const maxPadding = 16
+	buf := make([]byte, maxPadding+len(l.firstMagicItemName)+1)
+	n, err := r.ReadAt(buf, l.magicItemNamesOffset)
+	if n != len(buf) && err != nil {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimLeft(buf, "\x00"), append([]byte(l.firstMagicItemName), 0))
+}
+
+// Executable is a release of Dark Omen's PRG_ENG/ENGREL.EXE executable file.
+type Executable struct {
+	r        io.ReaderAt
+	language Language
+
+	spriteNamesOffset int64
+	spriteNamesErr    error
+
+	magicItemNamesOffset int64
+	magicItemNamesErr    error
+
+	magicItemNamesOnce sync.Once
+	magicItemNames     []string
+}
+
+// Open locates the tables of the ENGREL.EXE executable in r and returns an
+// Executable that reads them from r.
+//
+// Each table is found by searching the executable's .data and .rdata sections
+// for an anchor, so that patched and differently linked executables can be
+// read. If the PE headers cannot be parsed or an anchor is not found, the
+// table is looked for at its offset in each known release. The magic item
+// names are localized, and the release whose names are found identifies the
+// executable's language.
+//
+// If only some tables are found, the methods that read the other tables return
+// an *AnchorError. If no tables are found, Open returns an error that wraps
+// ErrUnknownExecutable and an *AnchorError for each table.
+func Open(r io.ReaderAt) (*Executable, error) {
+	sections, sectionsErr := readDataSections(r)
+
+	e := &Executable{r: r}
+	var l *layout
+	e.magicItemNamesOffset, l, e.magicItemNamesErr = locateMagicItemNames(r, sections, sectionsErr)
+	if l != nil {
+		e.language = l.language
+	}
+	e.spriteNamesOffset, e.spriteNamesErr = locateSpriteNames(r, sections, sectionsErr)
+
+	if e.spriteNamesErr != nil && e.magicItemNamesErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownExecutable, errors.Join(e.spriteNamesErr, e.magicItemNamesErr))
+	}
+	return e, nil
+}
+
+// Language returns the language of the executable's release, or
+// UnknownLanguage if its magic item names table was not found.
+func (e *Executable) Language() Language {
+	return e.language
+}
+
+// SpriteNames reads all sprite names from the executable and returns a slice
+// that can be indexed to return a sprite name at a particular position.
+func (e *Executable) SpriteNames() ([]string, error) {
+	if e.spriteNamesErr != nil {
+		return nil, e.spriteNamesErr
+	}
+	return readSpriteNames(e.r, e.spriteNamesOffset)
+}
+
+// SpriteName reads the sprite name at the given index from the executable.
+func (e *Executable) SpriteName(index int64) (string, error) {
+	if e.spriteNamesErr != nil {
+		return "", e.spriteNamesErr
+	}
+	return readSpriteName(e.r, e.spriteNamesOffset, index)
+}
+
+// MagicItemNames reads all magic item names from the executable and returns a
+// slice that can be indexed to return a magic item name at a particular
+// position. The table is read the first time it is needed and cached; the
+// returned slice must not be modified.
+func (e *Executable) MagicItemNames() ([]string, error) {
+	e.magicItemNamesOnce.Do(func() {
+		if e.magicItemNamesErr != nil {
+			return
+		}
+		e.magicItemNames, e.magicItemNamesErr = readMagicItemNames(e.r, e.magicItemNamesOffset)
+	})
+	return e.magicItemNames, e.magicItemNamesErr
+}
+
+// MagicItemName returns the magic item name at the given index in the
+// executable.
+func (e *Executable) MagicItemName(index int64) (string, error) {
+	if err := checkMagicItemIndex(index); err != nil {
+		return "", err
+	}
+	names, err := e.MagicItemNames()
+	if err != nil {
+		return "", err
+	}
+	return names[index], nil
+}
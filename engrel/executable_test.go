@@ -0,0 +1,202 @@
+package engrel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testSpriteNames returns the sprite names of a synthetic executable, which
+// include the name the table is anchored on.
+func testSpriteNames() []string {
+	names := make([]string, spriteCount)
+	for i := range names {
+		names[i] = fmt.Sprintf("SPRITE%03d.SPR", i)
+	}
+	names[1] = "BERNHD.SPR"
+	return names
+}
+
+// testMagicItemNames returns the magic item names of a synthetic executable,
+// which start with the real first and last names of the English release.
+func testMagicItemNames() []string {
+	names := make([]string, magicItemCount)
+	for i := range names {
+		names[i] = fmt.Sprintf("Magic Item %d", i)
+	}
+	names[0] = "Not used."
+	names[magicItemCount-1] = "SingleSanguine"
+	return names
+}
+
+// newTestImage returns a synthetic executable holding the sprite and magic
+// item names at the offsets of l.
+func newTestImage(l *layout, spriteNames, magicItemNames []string) []byte {
+	var magicItems []byte
+	// The table is padded and holds the names in reverse order.
+	magicItems = append(magicItems, 0, 0, 0, 0)
+	for i := len(magicItemNames) - 1; i >= 0; i-- {
+		magicItems = append(magicItems, magicItemNames[i]...)
+		magicItems = append(magicItems, 0, 0)
+	}
+
+	size := max(l.spriteNamesOffset+spriteCount*spriteNameSize, l.magicItemNamesOffset+int64(len(magicItems)))
+	bs := make([]byte, size)
+	for i, name := range spriteNames {
+		copy(bs[l.spriteNamesOffset+int64(i)*spriteNameSize:], name)
+	}
+	copy(bs[l.magicItemNamesOffset:], magicItems)
+	return bs
+}
+
+func TestOpen(t *testing.T) {
+	l := &layouts[0]
+	bs := newTestImage(l, testSpriteNames(), testMagicItemNames())
+
+	e, err := Open(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+	if got := e.Language(); got != English {
+		t.Errorf("Language() = %v, want %v", got, English)
+	}
+
+	spriteNames, err := e.SpriteNames()
+	if err != nil {
+		t.Fatalf("SpriteNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testSpriteNames(), spriteNames); diff != "" {
+		t.Errorf("SpriteNames() mismatch (-want +got):\n%s", diff)
+	}
+	spriteName, err := e.SpriteName(42)
+	if err != nil {
+		t.Fatalf("SpriteName() error = %v, want nil", err)
+	}
+	if want := "SPRITE042.SPR"; spriteName != want {
+		t.Errorf("SpriteName() = %v, want %v", spriteName, want)
+	}
+
+	magicItemNames, err := e.MagicItemNames()
+	if err != nil {
+		t.Fatalf("MagicItemNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testMagicItemNames(), magicItemNames); diff != "" {
+		t.Errorf("MagicItemNames() mismatch (-want +got):\n%s", diff)
+	}
+	magicItemName, err := e.MagicItemName(0)
+	if err != nil {
+		t.Fatalf("MagicItemName() error = %v, want nil", err)
+	}
+	if want := "Not used."; magicItemName != want {
+		t.Errorf("MagicItemName() = %v, want %v", magicItemName, want)
+	}
+	if _, err := e.MagicItemName(magicItemCount); err == nil {
+		t.Error("MagicItemName() error = nil, want error")
+	}
+}
+
+func TestOpen_unknown(t *testing.T) {
+	tests := []struct {
+		name string
+		bs   []byte
+	}{
+		{name: "empty", bs: nil},
+		{name: "zeros", bs: make([]byte, magicItemNamesStartOffset+1024)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(bytes.NewReader(tt.bs)); !errors.Is(err, ErrUnknownExecutable) {
+				t.Errorf("Open() error = %v, want %v", err, ErrUnknownExecutable)
+			}
+		})
+	}
+}
+
+func TestOpen_unknownLanguage(t *testing.T) {
+	localized := testMagicItemNames()
+	localized[magicItemCount-1] = "Unbekannt"
+	bs := newTestImage(&layouts[0], testSpriteNames(), localized)
+
+	e, err := Open(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+	if got := e.Language(); got != UnknownLanguage {
+		t.Errorf("Language() = %v, want %v", got, UnknownLanguage)
+	}
+
+	// The sprite names are found at the offset of a known release, so they can
+	// be read even though the release is not known.
+	spriteNames, err := e.SpriteNames()
+	if err != nil {
+		t.Fatalf("SpriteNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testSpriteNames(), spriteNames); diff != "" {
+		t.Errorf("SpriteNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	var anchorErr *AnchorError
+	if _, err := e.MagicItemNames(); !errors.As(err, &anchorErr) {
+		t.Errorf("MagicItemNames() error = %v, want *AnchorError", err)
+	}
+}
+
+func TestOpen_spriteNamesNotFound(t *testing.T) {
+	// The magic item names identify the release, but its sprite names table is
+	// not at the release's offset.
+	bs := newTestImage(&layouts[0], nil, testMagicItemNames())
+
+	e, err := Open(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+
+	var anchorErr *AnchorError
+	if _, err := e.SpriteNames(); !errors.As(err, &anchorErr) {
+		t.Errorf("SpriteNames() error = %v, want *AnchorError", err)
+	}
+	if _, err := e.SpriteName(0); !errors.As(err, &anchorErr) {
+		t.Errorf("SpriteName() error = %v, want *AnchorError", err)
+	}
+}
+
+func TestOpenReal(t *testing.T) {
+	darkOmenPath := runIfDarkOmenPathSet(t)
+
+	bs, err := os.ReadFile(path.Join(darkOmenPath, "DARKOMEN", "DARKOMEN", "PRG_ENG", "ENGREL.EXE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Open(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+	if got := e.Language(); got != English {
+		t.Errorf("Language() = %v, want %v", got, English)
+	}
+	name, err := e.MagicItemName(1)
+	if err != nil {
+		t.Fatalf("MagicItemName() error = %v, want nil", err)
+	}
+	if want := "Grudgebringer Sword"; name != want {
+		t.Errorf("MagicItemName() = %v, want %v", name, want)
+	}
+
+	spriteNames, err := e.SpriteNames()
+	if err != nil {
+		t.Fatalf("SpriteNames() error = %v, want nil", err)
+	}
+	if !slices.ContainsFunc(spriteNames, func(name string) bool {
+		return strings.Contains(strings.ToUpper(name), "BERNHD")
+	}) {
+		t.Errorf("SpriteNames() = %q, want a name containing BERNHD", spriteNames)
+	}
+}
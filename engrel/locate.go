@@ -0,0 +1,178 @@
+package engrel
+
+import (
+	"bytes"
+	"debug/pe"
+	"fmt"
+	"io"
+)
+
+// dataSections are the names of the PE sections that are searched for tables.
+var dataSections = []string{".data", ".rdata"}
+
+// AnchorError is returned when a table cannot be found in an executable, either
+// by searching its data sections for the table's anchor or at the table's
+// offset in a known release.
+type AnchorError struct {
+	// Table is the name of the table, e.g. "sprite names".
+	Table string
+	// Anchor describes what was searched for.
+	Anchor string
+	// Err is the reason the data sections could not be searched, or nil if they
+	// were searched.
+	Err error
+}
+
+func (e *AnchorError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("could not find %s table: could not search for %s: %v", e.Table, e.Anchor, e.Err)
+	}
+	return fmt.Sprintf("could not find %s table: %s not found", e.Table, e.Anchor)
+}
+
+func (e *AnchorError) Unwrap() error { return e.Err }
+
+// section is the contents of a data section and its offset in the file.
+type section struct {
+	offset int64
+	data   []byte
+}
+
+// readDataSections parses the PE headers of the executable in r and returns its
+// data sections.
+func readDataSections(r io.ReaderAt) ([]section, error) {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse PE headers: %w", err)
+	}
+	defer f.Close()
+
+	var sections []section
+	for _, name := range dataSections {
+		s := f.Section(name)
+		if s == nil || s.Offset == 0 {
+			continue
+		}
+		data, err := s.Data()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s section: %w", name, err)
+		}
+		sections = append(sections, section{offset: int64(s.Offset), data: data})
+	}
+	return sections, nil
+}
+
+// locateMagicItemNames returns the offset of the magic item names table in r
+// and the layout of the release it belongs to. The data sections are searched
+// for the first name in the table of each known release, and the table is
+// otherwise looked for at the offset of each known release.
+func locateMagicItemNames(r io.ReaderAt, sections []section, sectionsErr error) (int64, *layout, error) {
+	for i := range layouts {
+		l := &layouts[i]
+		// The anchor is a whole C string.
+		anchor := append(append([]byte{0}, l.firstMagicItemName...), 0)
+		for _, s := range sections {
+			if j := bytes.Index(s.data, anchor); j >= 0 {
+				return s.offset + int64(j) + 1, l, nil
+			}
+		}
+	}
+
+	for i := range layouts {
+		if layouts[i].matches(r) {
+			return layouts[i].magicItemNamesOffset, &layouts[i], nil
+		}
+	}
+
+	return 0, nil, &AnchorError{
+		Table:  "magic item names",
+		Anchor: "the first magic item name of a known release",
+		Err:    sectionsErr,
+	}
+}
+
+// spriteNameAnchors are names that the sprite names table of every release is
+// expected to hold. BERNHD is the sprite of Morgan Bernhardt, the player's
+// general. The names are matched without regard to case.
+var spriteNameAnchors = []string{"BERNHD"}
+
+// locateSpriteNames returns the offset of the sprite names table in r. The data
+// sections are searched for a name in spriteNameAnchors, and the table is the
+// earliest run of spriteCount NUL-padded names of spriteNameSize bytes that
+// holds the name in one of its records. The table is otherwise looked for at
+// the offsets of the known releases.
+func locateSpriteNames(r io.ReaderAt, sections []section, sectionsErr error) (int64, error) {
+	for _, anchor := range spriteNameAnchors {
+		for _, s := range sections {
+			if j, ok := findSpriteNamesTable(s.data, []byte(anchor)); ok {
+				return s.offset + int64(j), nil
+			}
+		}
+	}
+
+	for _, known := range layouts {
+		buf := make([]byte, spriteCount*spriteNameSize)
+		if n, _ := r.ReadAt(buf, known.spriteNamesOffset); n == len(buf) && isSpriteNamesTable(buf) {
+			return known.spriteNamesOffset, nil
+		}
+	}
+
+	return 0, &AnchorError{
+		Table:  "sprite names",
+		Anchor: fmt.Sprintf("a table of %d NUL-padded names of %d bytes holding one of %q", spriteCount, spriteNameSize, spriteNameAnchors),
+		Err:    sectionsErr,
+	}
+}
+
+// findSpriteNamesTable returns the offset in data of the earliest sprite names
+// table that holds anchor in the name of one of its records.
+func findSpriteNamesTable(data, anchor []byte) (int, bool) {
+	const size = spriteCount * spriteNameSize
+	for i := 0; i+len(anchor) <= len(data); i++ {
+		if !bytes.EqualFold(data[i:i+len(anchor)], anchor) {
+			continue
+		}
+		for j := max(0, i-size+1); j <= i && j+size <= len(data); j++ {
+			// The anchor must be in the name of the record it falls in.
+			k := (i - j) % spriteNameSize
+			if k+len(anchor) > spriteNameSize || bytes.IndexByte(data[i-k:i+len(anchor)], 0) >= 0 {
+				continue
+			}
+			if isSpriteNamesTable(data[j : j+size]) {
+				return j, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isSpriteNamesTable reports whether bs has the shape of the sprite names
+// table. Every name must be printable and padded with NULs, the first name must
+// not be empty and no more than half of the names may be empty.
+func isSpriteNamesTable(bs []byte) bool {
+	var empty int
+	for i := 0; i < spriteCount; i++ {
+		name := bs[i*spriteNameSize : (i+1)*spriteNameSize]
+		n := bytes.IndexByte(name, 0)
+		if n < 0 {
+			return false
+		}
+		if n == 0 {
+			if i == 0 {
+				return false
+			}
+			empty++
+		}
+		for _, b := range name[:n] {
+			if b < ' ' || b > '~' {
+				return false
+			}
+		}
+		for _, b := range name[n:] {
+			if b != 0 {
+				return false
+			}
+		}
+	}
+	return empty <= spriteCount/2
+}
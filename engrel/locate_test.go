@@ -0,0 +1,198 @@
+package engrel
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testSection is a section of a synthetic PE executable.
+type testSection struct {
+	name string
+	data []byte
+}
+
+// newTestPE returns a synthetic PE executable with the given sections. Only
+// the headers that debug/pe needs to find the sections are written.
+func newTestPE(t *testing.T, sections ...testSection) []byte {
+	t.Helper()
+
+	const (
+		peHeaderOffset = 0x40
+		fileAlignment  = 0x200
+	)
+
+	var buf bytes.Buffer
+	dos := make([]byte, peHeaderOffset)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3C:], peHeaderOffset)
+	buf.Write(dos)
+	buf.WriteString("PE\x00\x00")
+
+	write := func(v any) {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(pe.FileHeader{
+		Machine:          pe.IMAGE_FILE_MACHINE_I386,
+		NumberOfSections: uint16(len(sections)),
+	})
+
+	headersSize := peHeaderOffset + 4 + binary.Size(pe.FileHeader{}) + len(sections)*binary.Size(pe.SectionHeader32{})
+	offset := (headersSize + fileAlignment - 1) / fileAlignment * fileAlignment
+	for i, s := range sections {
+		var h pe.SectionHeader32
+		copy(h.Name[:], s.name)
+		h.VirtualSize = uint32(len(s.data))
+		h.VirtualAddress = uint32(0x1000 * (i + 1))
+		h.SizeOfRawData = uint32(len(s.data))
+		h.PointerToRawData = uint32(offset)
+		write(h)
+		offset += (len(s.data) + fileAlignment - 1) / fileAlignment * fileAlignment
+	}
+
+	for _, s := range sections {
+		for buf.Len()%fileAlignment != 0 {
+			buf.WriteByte(0)
+		}
+		buf.Write(s.data)
+	}
+	return buf.Bytes()
+}
+
+func TestOpen_pe(t *testing.T) {
+	// The tables are at different offsets than in any known release.
+	spriteNames := make([]byte, 100, 100+spriteCount*spriteNameSize)
+	for _, name := range testSpriteNames() {
+		record := make([]byte, spriteNameSize)
+		copy(record, name)
+		spriteNames = append(spriteNames, record...)
+	}
+	magicItemNames := []byte("some other string\x00\x00\x00")
+	names := testMagicItemNames()
+	for i := len(names) - 1; i >= 0; i-- {
+		magicItemNames = append(magicItemNames, names[i]...)
+		magicItemNames = append(magicItemNames, 0)
+	}
+
+	bs := newTestPE(t,
+		testSection{name: ".text", data: bytes.Repeat([]byte{0x90}, 0x300)},
+		testSection{name: ".rdata", data: spriteNames},
+		testSection{name: ".data", data: magicItemNames},
+	)
+
+	e, err := Open(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+	if got := e.Language(); got != English {
+		t.Errorf("Language() = %v, want %v", got, English)
+	}
+
+	gotSpriteNames, err := e.SpriteNames()
+	if err != nil {
+		t.Fatalf("SpriteNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testSpriteNames(), gotSpriteNames); diff != "" {
+		t.Errorf("SpriteNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	gotMagicItemNames, err := e.MagicItemNames()
+	if err != nil {
+		t.Fatalf("MagicItemNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testMagicItemNames(), gotMagicItemNames); diff != "" {
+		t.Errorf("MagicItemNames() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpen_peSpriteNamesAfterSimilarTable(t *testing.T) {
+	record := func(name string) []byte {
+		bs := make([]byte, spriteNameSize)
+		copy(bs, name)
+		return bs
+	}
+	// A table of the same shape without the anchor comes first.
+	var data []byte
+	for i := 0; i < spriteCount; i++ {
+		data = append(data, record(fmt.Sprintf("OTHER%03d", i))...)
+	}
+	data = append(data, 0xFF, 0xFF, 0xFF, 0xFF)
+	for _, name := range testSpriteNames() {
+		data = append(data, record(name)...)
+	}
+
+	bs := newTestPE(t, testSection{name: ".rdata", data: data})
+
+	e, err := Open(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+	gotSpriteNames, err := e.SpriteNames()
+	if err != nil {
+		t.Fatalf("SpriteNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testSpriteNames(), gotSpriteNames); diff != "" {
+		t.Errorf("SpriteNames() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpen_peWithoutAnchors(t *testing.T) {
+	bs := newTestPE(t,
+		testSection{name: ".text", data: bytes.Repeat([]byte{0x90}, 0x300)},
+		testSection{name: ".data", data: []byte("not a table\x00")},
+	)
+
+	_, err := Open(bytes.NewReader(bs))
+	if !errors.Is(err, ErrUnknownExecutable) {
+		t.Errorf("Open() error = %v, want %v", err, ErrUnknownExecutable)
+	}
+
+	var anchorErr *AnchorError
+	if !errors.As(err, &anchorErr) {
+		t.Fatalf("Open() error = %v, want *AnchorError", err)
+	}
+	if anchorErr.Err != nil {
+		t.Errorf("AnchorError.Err = %v, want nil", anchorErr.Err)
+	}
+}
+
+func TestIsSpriteNamesTable(t *testing.T) {
+	table := func(names ...string) []byte {
+		bs := make([]byte, spriteCount*spriteNameSize)
+		for i, name := range names {
+			copy(bs[i*spriteNameSize:], name)
+		}
+		return bs
+	}
+	full := make([]string, spriteCount)
+	copy(full, testSpriteNames())
+
+	tests := []struct {
+		name string
+		bs   []byte
+		want bool
+	}{
+		{name: "table", bs: table(full...), want: true},
+		{name: "zeros", bs: table()},
+		{name: "first name empty", bs: table(append([]string{""}, full[1:]...)...)},
+		{name: "mostly empty", bs: table(full[:spriteCount/3]...)},
+		{name: "unprintable", bs: table(append([]string{"BAD\x01NAME"}, full[1:]...)...)},
+		{name: "not padded", bs: table(append([]string{"NAME\x00JUNK"}, full[1:]...)...)},
+		{name: "not terminated", bs: table(append([]string{string(bytes.Repeat([]byte{'A'}, spriteNameSize))}, full[1:]...)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSpriteNamesTable(tt.bs); got != tt.want {
+				t.Errorf("isSpriteNamesTable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
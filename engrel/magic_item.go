@@ -3,69 +3,138 @@ package engrel
 import (
 	"fmt"
 	"io"
+	"math"
 )
 
 const (
 	magicItemNamesStartOffset = 0xDB374
 	magicItemCount            = 64
+	// magicItemNamesChunkSize is the number of bytes of the magic item names
+	// table that are read at a time.
+	magicItemNamesChunkSize = 1024
 )
 
+// ReadMagicItemNamesAt reads all magic item names from r and returns a slice
+// that can be indexed to return a magic item name at a particular position.
+// The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
+// found in Dark Omen.
+func ReadMagicItemNamesAt(r io.ReaderAt) ([]string, error) {
+	return readMagicItemNames(r, magicItemNamesStartOffset)
+}
+
+// ReadMagicItemNameAt reads the magic item name at the given index from r.
+// The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
+// found in Dark Omen. To look up many names, use ReadMagicItemNamesAt or an
+// Executable, which read the table once.
+func ReadMagicItemNameAt(r io.ReaderAt, index int64) (string, error) {
+	if err := checkMagicItemIndex(index); err != nil {
+		return "", err
+	}
+	names, err := readMagicItemNames(r, magicItemNamesStartOffset)
+	if err != nil {
+		return "", err
+	}
+	return names[index], nil
+}
+
 // ReadMagicItemNames reads all magic item names from r and returns a slice that
 // can be indexed to return a magic item name at a particular position.
 // The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
 // found in Dark Omen.
+//
+// Deprecated: ReadMagicItemNames reads every byte of the executable before the
+// table. Use ReadMagicItemNamesAt or Open, which read only the table.
 func ReadMagicItemNames(r io.ByteReader) ([]string, error) {
-	return readMagicItemNames(r)
+	for i := 0; i < magicItemNamesStartOffset; i++ {
+		if _, err := r.ReadByte(); err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	return decodeMagicItemNames(byteReader{r})
 }
 
 // ReadMagicItemName reads the magic item name at the given index from r.
 // The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
 // found in Dark Omen.
+//
+// Deprecated: ReadMagicItemName reads every byte of the executable before the
+// table. Use ReadMagicItemNameAt or Open, which read only the table.
 func ReadMagicItemName(r io.ByteReader, index int64) (string, error) {
-	if index > magicItemCount-1 {
-		return "", fmt.Errorf("expected index to be less than %d, got %d", magicItemCount, index)
+	if err := checkMagicItemIndex(index); err != nil {
+		return "", err
 	}
-	items, err := readMagicItemNames(r)
+	names, err := ReadMagicItemNames(r)
 	if err != nil {
 		return "", err
 	}
-	return items[index], nil
+	return names[index], nil
+}
+
+func checkMagicItemIndex(index int64) error {
+	if index < 0 || index > magicItemCount-1 {
+		return fmt.Errorf("expected index to be less than %d, got %d", magicItemCount, index)
+	}
+	return nil
+}
+
+// readMagicItemNames reads the magic item names from the table at offset in r.
+func readMagicItemNames(r io.ReaderAt, offset int64) ([]string, error) {
+	return decodeMagicItemNames(io.NewSectionReader(r, offset, math.MaxInt64-offset))
 }
 
-func readMagicItemNames(r io.ByteReader) ([]string, error) {
+// decodeMagicItemNames reads the magic item names from the table at the start
+// of r. The table is a list of NUL-terminated names, which may be separated by
+// padding. The names are stored from the last magic item to the first.
+func decodeMagicItemNames(r io.Reader) ([]string, error) {
 	names := make([]string, magicItemCount)
 
-	var n int
-	var i int
-	var bs []byte
+	var (
+		i   int
+		bs  []byte
+		buf = make([]byte, magicItemNamesChunkSize)
+	)
 	for {
-		b, err := r.ReadByte()
-		if err != nil {
-			if err == io.EOF {
-				return nil, io.ErrUnexpectedEOF
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if b != 0 {
+				bs = append(bs, b)
+				continue
 			}
-			return nil, err
-		}
-		n++
-		// Hacky way of reading and ignoring all of the previous bytes until the
-		// offset of interest.
-		if n <= magicItemNamesStartOffset {
-			continue
-		}
-		if b == '\x00' {
-			s := string(bs)
-			if s == "" {
-				bs = nil
+			if len(bs) == 0 {
+				// Padding.
 				continue
 			}
-			names[len(names)-1-i] = s
+			names[len(names)-1-i] = string(bs)
 			i++
 			if i == magicItemCount {
 				return names, nil
 			}
-			bs = nil
-			continue
+			bs = bs[:0]
 		}
-		bs = append(bs, b)
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
+
+// byteReader adapts an io.ByteReader to an io.Reader.
+type byteReader struct {
+	r io.ByteReader
+}
+
+func (r byteReader) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		p[n], err = r.r.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		n++
 	}
+	return n, nil
 }
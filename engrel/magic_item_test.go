@@ -1,10 +1,16 @@
 package engrel
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func runIfDarkOmenPathSet(t *testing.T) string {
@@ -102,3 +108,196 @@ func TestReadMagicItemNameReal(t *testing.T) {
 		})
 	}
 }
+
+func TestReadMagicItemNamesAtReal(t *testing.T) {
+	darkOmenPath := runIfDarkOmenPathSet(t)
+
+	bs, err := os.ReadFile(path.Join(darkOmenPath, "DARKOMEN", "DARKOMEN", "PRG_ENG", "ENGREL.EXE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		wantLen   int
+		wantNames map[int]string
+		wantErr   bool
+	}{
+		{
+			name:    "correct length and names",
+			wantLen: 64,
+			wantNames: map[int]string{
+				0:  "Not used.",
+				1:  "Grudgebringer Sword",
+				42: "Brain Bursta",
+				63: "SingleSanguine",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(bs)
+
+			got, err := ReadMagicItemNamesAt(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadMagicItemNamesAt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotLen := len(got); gotLen != tt.wantLen {
+				t.Errorf("len(ReadMagicItemNamesAt()) = %v, want %v", gotLen, tt.wantLen)
+			}
+			for index, wantName := range tt.wantNames {
+				if gotName := got[index]; gotName != wantName {
+					t.Errorf("ReadMagicItemNamesAt()[%d] = %v, want %v", index, gotName, wantName)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMagicItemNameAtReal(t *testing.T) {
+	darkOmenPath := runIfDarkOmenPathSet(t)
+
+	bs, err := os.ReadFile(path.Join(darkOmenPath, "DARKOMEN", "DARKOMEN", "PRG_ENG", "ENGREL.EXE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		index   int64
+		wantErr bool
+	}{
+		{
+			name:  "Grudgebringer Sword",
+			index: 1,
+		},
+		{
+			name:  "Enchanted Shield",
+			index: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(bs)
+
+			got, err := ReadMagicItemNameAt(r, tt.index)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadMagicItemNameAt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.name {
+				t.Errorf("ReadMagicItemNameAt() = %v, want %v", got, tt.name)
+			}
+		})
+	}
+}
+
+func TestReadMagicItemNamesAt(t *testing.T) {
+	bs := newTestImage(&layouts[0], testSpriteNames(), testMagicItemNames())
+
+	got, err := ReadMagicItemNamesAt(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("ReadMagicItemNamesAt() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testMagicItemNames(), got); diff != "" {
+		t.Errorf("ReadMagicItemNamesAt() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadMagicItemNameAt(t *testing.T) {
+	bs := newTestImage(&layouts[0], testSpriteNames(), testMagicItemNames())
+
+	tests := []struct {
+		index   int64
+		want    string
+		wantErr bool
+	}{
+		{index: 0, want: "Not used."},
+		{index: 1, want: "Magic Item 1"},
+		{index: magicItemCount - 1, want: "SingleSanguine"},
+		{index: -1, wantErr: true},
+		{index: magicItemCount, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.index), func(t *testing.T) {
+			got, err := ReadMagicItemNameAt(bytes.NewReader(bs), tt.index)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadMagicItemNameAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadMagicItemNameAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMagicItemNamesAt_truncated(t *testing.T) {
+	bs := newTestImage(&layouts[0], testSpriteNames(), testMagicItemNames())
+
+	_, err := ReadMagicItemNamesAt(bytes.NewReader(bs[:len(bs)-100]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadMagicItemNamesAt() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestReadMagicItemNames(t *testing.T) {
+	bs := newTestImage(&layouts[0], testSpriteNames(), testMagicItemNames())
+
+	// The deprecated functions accept readers that only implement
+	// io.ByteReader.
+	got, err := ReadMagicItemNames(bufio.NewReader(bytes.NewReader(bs)))
+	if err != nil {
+		t.Fatalf("ReadMagicItemNames() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff(testMagicItemNames(), got); diff != "" {
+		t.Errorf("ReadMagicItemNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	name, err := ReadMagicItemName(bufio.NewReader(bytes.NewReader(bs)), 1)
+	if err != nil {
+		t.Fatalf("ReadMagicItemName() error = %v, want nil", err)
+	}
+	if want := "Magic Item 1"; name != want {
+		t.Errorf("ReadMagicItemName() = %v, want %v", name, want)
+	}
+
+	_, err = ReadMagicItemNames(bufio.NewReader(bytes.NewReader(bs[:len(bs)-100])))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadMagicItemNames() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+// countingReaderAt counts the calls to ReadAt.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.reads++
+	return r.r.ReadAt(p, off)
+}
+
+func TestExecutable_MagicItemNameCached(t *testing.T) {
+	r := &countingReaderAt{r: bytes.NewReader(newTestImage(&layouts[0], testSpriteNames(), testMagicItemNames()))}
+
+	e, err := Open(r)
+	if err != nil {
+		t.Fatalf("Open() error = %v, want nil", err)
+	}
+
+	if _, err := e.MagicItemName(0); err != nil {
+		t.Fatalf("MagicItemName() error = %v, want nil", err)
+	}
+	reads := r.reads
+	for index := int64(0); index < magicItemCount; index++ {
+		want := testMagicItemNames()[index]
+		if got, err := e.MagicItemName(index); err != nil || got != want {
+			t.Errorf("MagicItemName(%d) = %v, %v, want %v, nil", index, got, err, want)
+		}
+	}
+	if r.reads != reads {
+		t.Errorf("MagicItemName() read the executable %d more time(s), want 0", r.reads-reads)
+	}
+}
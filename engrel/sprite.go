@@ -18,10 +18,22 @@ const (
 // The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
 // found in Dark Omen.
 func ReadSpriteNames(r io.ReaderAt) ([]string, error) {
+	return readSpriteNames(r, spriteNamesStartOffset)
+}
+
+// ReadSpriteName reads the sprite name at the given index from r.
+// The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
+// found in Dark Omen.
+func ReadSpriteName(r io.ReaderAt, index int64) (string, error) {
+	return readSpriteName(r, spriteNamesStartOffset, index)
+}
+
+// readSpriteNames reads all sprite names from the table at offset in r.
+func readSpriteNames(r io.ReaderAt, offset int64) ([]string, error) {
 	names := make([]string, spriteCount)
 
 	for index := int64(0); index < spriteCount; index++ {
-		name, err := readSpriteName(r, index)
+		name, err := readSpriteName(r, offset, index)
 		if err != nil {
 			return nil, fmt.Errorf("could not read sprite name at index %d: %w", index, err)
 		}
@@ -31,19 +43,14 @@ func ReadSpriteNames(r io.ReaderAt) ([]string, error) {
 	return names, nil
 }
 
-// ReadSpriteName reads the sprite name at the given index from r.
-// The given reader should contain the contents of the PRG_ENG/ENGREL.EXE file
-// found in Dark Omen.
-func ReadSpriteName(r io.ReaderAt, index int64) (string, error) {
-	return readSpriteName(r, index)
-}
-
-func readSpriteName(r io.ReaderAt, index int64) (string, error) {
+// readSpriteName reads the sprite name at the given index from the table at
+// offset in r.
+func readSpriteName(r io.ReaderAt, offset, index int64) (string, error) {
 	if index > spriteCount-1 {
 		return "", fmt.Errorf("expected index to be less than %d, got %d", spriteCount, index)
 	}
 	buf := make([]byte, spriteNameSize)
-	n, err := r.ReadAt(buf, spriteNamesStartOffset+spriteNameSize*index)
+	n, err := r.ReadAt(buf, offset+spriteNameSize*index)
 	if n != spriteNameSize {
 		return "", fmt.Errorf("read %d byte(s), expected %d", n, spriteNameSize)
 	}
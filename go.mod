@@ -7,9 +7,13 @@ require (
 	github.com/go-audio/audio v1.0.0
 	github.com/go-audio/wav v1.1.0
 	github.com/google/go-cmp v0.6.0
+	github.com/mewkiz/flac v1.0.12
+	golang.org/x/image v0.10.0
+	golang.org/x/text v0.11.0
 )
 
 require (
 	github.com/go-audio/riff v1.0.0 // indirect
-	golang.org/x/image v0.10.0 // indirect
+	github.com/icza/bitio v1.1.0 // indirect
+	github.com/mewkiz/pkg v0.0.0-20230226050401-4010bf0fec14 // indirect
 )
@@ -1,6 +0,0 @@
-package audio
-
-type Block interface {
-	Bytes() ([]byte, error)
-	AsPCM16Block() *PCM16Block
-}
@@ -0,0 +1,43 @@
+package audio
+
+import (
+	"encoding/binary"
+
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+)
+
+// BlockDecoder decodes the ADPCM blocks of MAD and SAD streams into
+// interleaved 16-bit little-endian PCM samples, reusing its buffers from one
+// block to the next. The zero value is ready to use.
+type BlockDecoder struct {
+	data    [][]byte
+	samples [][]int16
+	buf     []byte
+}
+
+// Decode decodes a block with one channel for each of the given initial samples
+// and step indexes. The ADPCM data of the channels is interleaved every 4
+// bytes. The returned bytes are only valid until the next call to Decode.
+func (d *BlockDecoder) Decode(samples, indexes []int16, data []byte) []byte {
+	channels := len(samples)
+	if len(d.data) != channels {
+		d.data = make([][]byte, channels)
+		d.samples = make([][]int16, channels)
+	}
+
+	for c := range channels {
+		d.data[c] = d.data[c][:0]
+		for i := c * 4; i+4 <= len(data); i += channels * 4 {
+			d.data[c] = append(d.data[c], data[i:i+4]...)
+		}
+		d.samples[c] = adpcm.NewBlock(samples[c], indexes[c], d.data[c]).AppendPCM16(d.samples[c][:0])
+	}
+
+	d.buf = d.buf[:0]
+	for i := range d.samples[0] {
+		for c := range channels {
+			d.buf = binary.LittleEndian.AppendUint16(d.buf, uint16(d.samples[c][i]))
+		}
+	}
+	return d.buf
+}
@@ -0,0 +1,46 @@
+package audio
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jonathaningram/dark-omen/audio/adpcm"
+)
+
+func TestBlockDecoder_Decode(t *testing.T) {
+	left := make([]byte, 16)
+	right := make([]byte, 16)
+	for i := range left {
+		left[i] = byte(i * 17)
+		right[i] = byte(255 - i*13)
+	}
+
+	// The channels are interleaved every 4 bytes.
+	var stereo []byte
+	for i := 0; i < len(left); i += 4 {
+		stereo = append(stereo, left[i:i+4]...)
+		stereo = append(stereo, right[i:i+4]...)
+	}
+
+	pcm := func(channels ...[]int16) []byte {
+		var bs []byte
+		for i := range channels[0] {
+			for _, c := range channels {
+				bs = binary.LittleEndian.AppendUint16(bs, uint16(c[i]))
+			}
+		}
+		return bs
+	}
+	leftSamples := adpcm.NewBlock(100, 10, left).AppendPCM16(nil)
+	rightSamples := adpcm.NewBlock(-200, 20, right).AppendPCM16(nil)
+
+	var d BlockDecoder
+	if diff := cmp.Diff(pcm(leftSamples), d.Decode([]int16{100}, []int16{10}, left)); diff != "" {
+		t.Errorf("Decode() mono mismatch (-want +got):\n%s", diff)
+	}
+	// The decoder can be reused for a different number of channels.
+	if diff := cmp.Diff(pcm(leftSamples, rightSamples), d.Decode([]int16{100, -200}, []int16{10, 20}, stereo)); diff != "" {
+		t.Errorf("Decode() stereo mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// Mix returns the 16-bit PCM samples of a with those of b, scaled by gain,
+// added to them. The result is as long as the longer of a and b, and samples
+// that would exceed 16 bits are clipped.
+func Mix(a, b []int16, gain float64) []int16 {
+	out := make([]int16, max(len(a), len(b)))
+	for i := range out {
+		var v float64
+		if i < len(a) {
+			v = float64(a[i])
+		}
+		if i < len(b) {
+			v += gain * float64(b[i])
+		}
+		out[i] = clamp16(math.Round(v))
+	}
+	return out
+}
+
+// FadeIn returns a copy of the 16-bit PCM samples with a linear fade from
+// silence over the first n samples. n is limited to the number of samples, and
+// a negative n is treated as zero.
+func FadeIn(samples []int16, n int) []int16 {
+	out := append([]int16(nil), samples...)
+	n = max(0, min(n, len(out)))
+	for i := range out[:n] {
+		out[i] = int16(math.Round(float64(out[i]) * float64(i) / float64(n)))
+	}
+	return out
+}
+
+// FadeOut returns a copy of the 16-bit PCM samples with a linear fade to
+// silence over the last n samples. n is limited to the number of samples, and a
+// negative n is treated as zero.
+func FadeOut(samples []int16, n int) []int16 {
+	out := append([]int16(nil), samples...)
+	n = max(0, min(n, len(out)))
+	for i := range out[len(out)-n:] {
+		j := len(out) - n + i
+		out[j] = int16(math.Round(float64(out[j]) * float64(n-1-i) / float64(n)))
+	}
+	return out
+}
+
+// NumSamples returns the number of samples played in d at sampleRate Hz,
+// rounded to the nearest sample.
+func NumSamples(sampleRate int, d time.Duration) int {
+	return int(math.Round(d.Seconds() * float64(sampleRate)))
+}
+
+// clamp16 returns v limited to the range of a 16-bit sample.
+func clamp16(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
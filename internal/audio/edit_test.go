@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMix(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []int16
+		gain float64
+		want []int16
+	}{
+		{
+			name: "same length",
+			a:    []int16{100, -100, 0},
+			b:    []int16{10, 20, -30},
+			gain: 1,
+			want: []int16{110, -80, -30},
+		},
+		{
+			name: "gain",
+			a:    []int16{100, 100},
+			b:    []int16{100, -100},
+			gain: 0.5,
+			want: []int16{150, 50},
+		},
+		{
+			name: "b longer",
+			a:    []int16{1},
+			b:    []int16{1, 2, 3},
+			gain: 1,
+			want: []int16{2, 2, 3},
+		},
+		{
+			name: "a longer",
+			a:    []int16{1, 2, 3},
+			b:    []int16{1},
+			gain: 2,
+			want: []int16{3, 2, 3},
+		},
+		{
+			name: "clipped",
+			a:    []int16{30000, -30000},
+			b:    []int16{30000, -30000},
+			gain: 1,
+			want: []int16{math.MaxInt16, math.MinInt16},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, Mix(tt.a, tt.b, tt.gain)); diff != "" {
+				t.Errorf("Mix() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFadeIn(t *testing.T) {
+	samples := []int16{1000, 1000, 1000, 1000, 1000, 1000}
+	tests := []struct {
+		name string
+		n    int
+		want []int16
+	}{
+		{name: "none", n: 0, want: samples},
+		{name: "negative", n: -1, want: samples},
+		{name: "part", n: 4, want: []int16{0, 250, 500, 750, 1000, 1000}},
+		{name: "longer than samples", n: 10, want: []int16{0, 167, 333, 500, 667, 833}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, FadeIn(samples, tt.n)); diff != "" {
+				t.Errorf("FadeIn() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFadeOut(t *testing.T) {
+	samples := []int16{1000, 1000, 1000, 1000, 1000, 1000}
+	tests := []struct {
+		name string
+		n    int
+		want []int16
+	}{
+		{name: "none", n: 0, want: samples},
+		{name: "negative", n: -1, want: samples},
+		{name: "part", n: 4, want: []int16{1000, 1000, 750, 500, 250, 0}},
+		{name: "longer than samples", n: 10, want: []int16{833, 667, 500, 333, 167, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, FadeOut(samples, tt.n)); diff != "" {
+				t.Errorf("FadeOut() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package audio
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+const (
+	// flacBlockSize is the number of samples in each channel of a FLAC frame.
+	// It is the block size used by the reference encoder at its default
+	// compression level.
+	flacBlockSize = 4096
+	// flacBitsPerSample is the sample size of the encoded audio.
+	flacBitsPerSample = 16
+	// flacMaxPartitionOrder is the largest Rice partition order that is tried
+	// for each subframe.
+	flacMaxPartitionOrder = 8
+	// flacMaxFixedOrder is the largest fixed prediction order in FLAC.
+	flacMaxFixedOrder = 4
+)
+
+// WriteFLAC writes the 16-bit PCM samples as a lossless FLAC stream at
+// sampleRate Hz to w. channels has one slice of samples per channel, and every
+// channel must have the same number of samples. Mono and stereo audio are
+// supported.
+//
+// Each subframe is encoded with whichever of the constant, verbatim and fixed
+// linear prediction methods is smallest. For stereo audio, each frame also uses
+// whichever of the left/right, left/side, side/right and mid/side channel
+// assignments is smallest.
+func WriteFLAC(w io.Writer, sampleRate int, channels [][]int16) error {
+	if n := len(channels); n != 1 && n != 2 {
+		return fmt.Errorf("unsupported channel count %d", n)
+	}
+	samples := len(channels[0])
+	for c, ch := range channels {
+		if len(ch) != samples {
+			return fmt.Errorf("channel %d has %d sample(s), channel 0 has %d", c, len(ch), samples)
+		}
+	}
+
+	// The MD5 signature in the stream info is of the interleaved little-endian
+	// samples.
+	h := md5.New()
+	var buf [2]byte
+	for i := 0; i < samples; i++ {
+		for _, ch := range channels {
+			binary.LittleEndian.PutUint16(buf[:], uint16(ch[i]))
+			h.Write(buf[:])
+		}
+	}
+
+	info := &meta.StreamInfo{
+		BlockSizeMin:  flacBlockSize,
+		BlockSizeMax:  flacBlockSize,
+		SampleRate:    uint32(sampleRate),
+		NChannels:     uint8(len(channels)),
+		BitsPerSample: flacBitsPerSample,
+		NSamples:      uint64(samples),
+	}
+	copy(info.MD5sum[:], h.Sum(nil))
+
+	bw := bufio.NewWriter(w)
+
+	// The encoder is only given an io.Writer so that it does not rewrite the
+	// stream info, which is already complete, or close w.
+	enc, err := flac.NewEncoder(struct{ io.Writer }{bw}, info)
+	if err != nil {
+		return fmt.Errorf("could not write FLAC stream info: %w", err)
+	}
+
+	for start := 0; start < samples; start += flacBlockSize {
+		end := min(start+flacBlockSize, samples)
+		f := newFLACFrame(sampleRate, channels, start, end)
+		if err := enc.WriteFrame(f); err != nil {
+			return fmt.Errorf("could not write FLAC frame at sample %d: %w", start, err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// newFLACFrame returns the frame holding the samples from start to end of each
+// channel.
+func newFLACFrame(sampleRate int, channels [][]int16, start, end int) *frame.Frame {
+	n := end - start
+
+	samples := make([][]int32, len(channels))
+	for c, ch := range channels {
+		samples[c] = make([]int32, n)
+		for i, v := range ch[start:end] {
+			samples[c][i] = int32(v)
+		}
+	}
+
+	f := &frame.Frame{
+		Header: frame.Header{
+			HasFixedBlockSize: true,
+			BlockSize:         uint16(n),
+			SampleRate:        uint32(sampleRate),
+			BitsPerSample:     flacBitsPerSample,
+		},
+	}
+
+	if len(channels) == 1 {
+		f.Channels = frame.ChannelsMono
+		f.Subframes = []*frame.Subframe{analyzeSubframe(samples[0], flacBitsPerSample).Subframe}
+		f.Subframes[0].Samples = samples[0]
+		return f
+	}
+
+	// The encoder decorrelates the channels itself, but the subframes are
+	// chosen by analyzing the decorrelated signals.
+	left, right := samples[0], samples[1]
+	mid := make([]int32, n)
+	side := make([]int32, n)
+	for i := range left {
+		mid[i] = int32((int64(left[i]) + int64(right[i])) >> 1)
+		side[i] = left[i] - right[i]
+	}
+
+	l := analyzeSubframe(left, flacBitsPerSample)
+	r := analyzeSubframe(right, flacBitsPerSample)
+	m := analyzeSubframe(mid, flacBitsPerSample)
+	s := analyzeSubframe(side, flacBitsPerSample+1)
+
+	type assignment struct {
+		channels frame.Channels
+		a, b     *analyzedSubframe
+	}
+	best := assignment{frame.ChannelsLR, l, r}
+	for _, a := range []assignment{
+		{frame.ChannelsLeftSide, l, s},
+		{frame.ChannelsSideRight, s, r},
+		{frame.ChannelsMidSide, m, s},
+	} {
+		if a.a.bits+a.b.bits < best.a.bits+best.b.bits {
+			best = a
+		}
+	}
+
+	f.Channels = best.channels
+	f.Subframes = []*frame.Subframe{best.a.Subframe, best.b.Subframe}
+	f.Subframes[0].Samples = left
+	f.Subframes[1].Samples = right
+	return f
+}
+
+// analyzedSubframe is a subframe and the number of bits it is encoded in.
+type analyzedSubframe struct {
+	*frame.Subframe
+	bits int
+}
+
+// analyzeSubframe returns the smallest subframe for samples, which have bps
+// bits per sample. The subframe's Samples are left nil for the caller to set.
+func analyzeSubframe(samples []int32, bps int) *analyzedSubframe {
+	n := len(samples)
+
+	constant := true
+	for _, v := range samples[1:] {
+		if v != samples[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		return &analyzedSubframe{
+			Subframe: &frame.Subframe{
+				SubHeader: frame.SubHeader{Pred: frame.PredConstant},
+				NSamples:  n,
+			},
+			bits: bps,
+		}
+	}
+
+	best := &analyzedSubframe{
+		Subframe: &frame.Subframe{
+			SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+			NSamples:  n,
+		},
+		bits: n * bps,
+	}
+
+	residuals := make([]int32, n)
+	for order := 0; order <= min(flacMaxFixedOrder, n-1); order++ {
+		fixedResiduals(samples, order, residuals)
+
+		rice, riceBits := analyzeResiduals(residuals[order:], order, n)
+		if bits := order*bps + riceBits; bits < best.bits {
+			best = &analyzedSubframe{
+				Subframe: &frame.Subframe{
+					SubHeader: frame.SubHeader{
+						Pred:                 frame.PredFixed,
+						Order:                order,
+						ResidualCodingMethod: rice.method,
+						RiceSubframe:         rice.RiceSubframe,
+					},
+					NSamples: n,
+				},
+				bits: bits,
+			}
+		}
+	}
+
+	return best
+}
+
+// fixedResiduals sets residuals[order:] to the residuals of samples predicted
+// by the fixed polynomial predictor of the given order.
+func fixedResiduals(samples []int32, order int, residuals []int32) {
+	for i := order; i < len(samples); i++ {
+		s := samples
+		var prediction int32
+		switch order {
+		case 1:
+			prediction = s[i-1]
+		case 2:
+			prediction = 2*s[i-1] - s[i-2]
+		case 3:
+			prediction = 3*s[i-1] - 3*s[i-2] + s[i-3]
+		case 4:
+			prediction = 4*s[i-1] - 6*s[i-2] + 4*s[i-3] - s[i-4]
+		}
+		residuals[i] = s[i] - prediction
+	}
+}
+
+type riceSubframe struct {
+	*frame.RiceSubframe
+	method frame.ResidualCodingMethod
+}
+
+// analyzeResiduals returns the Rice partitioning and parameters that encode
+// residuals, the residuals of a subframe of n samples with the given
+// prediction order, in the fewest bits, and that number of bits.
+func analyzeResiduals(residuals []int32, order, n int) (riceSubframe, int) {
+	folded := make([]uint32, len(residuals))
+	for i, v := range residuals {
+		folded[i] = uint32(v<<1) ^ uint32(v>>31)
+	}
+
+	var (
+		best     riceSubframe
+		bestBits = -1
+	)
+	for partOrder := 0; partOrder <= flacMaxPartitionOrder; partOrder++ {
+		parts := 1 << partOrder
+		// Every partition must be the same size, and the first must be large
+		// enough to also hold the warm-up samples.
+		if n%parts != 0 || n/parts < order {
+			break
+		}
+
+		rice := &frame.RiceSubframe{
+			PartOrder:  partOrder,
+			Partitions: make([]frame.RicePartition, parts),
+		}
+		var (
+			total    int
+			maxParam uint
+			offset   int
+		)
+		for p := range rice.Partitions {
+			size := n / parts
+			if p == 0 {
+				size -= order
+			}
+			param, bits := riceParam(folded[offset : offset+size])
+			rice.Partitions[p].Param = param
+			maxParam = max(maxParam, param)
+			total += bits
+			offset += size
+		}
+
+		// Rice parameters of 15 and above need the 5-bit parameters of the
+		// second coding method. 15 is an escape code in the first.
+		method, paramBits := frame.ResidualCodingMethodRice1, 4
+		if maxParam >= 15 {
+			method, paramBits = frame.ResidualCodingMethodRice2, 5
+		}
+		total += 2 + 4 + parts*paramBits
+
+		if bestBits < 0 || total < bestBits {
+			best = riceSubframe{RiceSubframe: rice, method: method}
+			bestBits = total
+		}
+	}
+
+	return best, bestBits
+}
+
+// riceParam returns the Rice parameter that encodes the folded residuals in the
+// fewest bits, and that number of bits.
+func riceParam(folded []uint32) (uint, int) {
+	if len(folded) == 0 {
+		return 0, 0
+	}
+
+	var sum uint64
+	for _, u := range folded {
+		sum += uint64(u)
+	}
+
+	// The best parameter is close to log2 of the mean, so only its neighbors
+	// are tried.
+	mean := sum / uint64(len(folded))
+	guess := 0
+	if mean > 0 {
+		guess = bits.Len64(mean) - 1
+	}
+
+	bestParam, bestBits := uint(0), -1
+	for k := max(0, guess-1); k <= min(30, guess+1); k++ {
+		// A residual is encoded as its high bits in unary with a stop bit,
+		// followed by its low k bits.
+		total := len(folded) * (k + 1)
+		for _, u := range folded {
+			total += int(u >> k)
+		}
+		if bestBits < 0 || total < bestBits {
+			bestParam, bestBits = uint(k), total
+		}
+	}
+	return bestParam, bestBits
+}
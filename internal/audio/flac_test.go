@@ -0,0 +1,165 @@
+package audio
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jonathaningram/dark-omen/internal/audiotest"
+	"github.com/mewkiz/flac"
+)
+
+// readFLAC decodes the FLAC stream in bs and returns its sample rate and
+// samples. It checks the stream's MD5 signature against the decoded samples.
+func readFLAC(t *testing.T, bs []byte) (int, [][]int16) {
+	t.Helper()
+
+	s, err := flac.New(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("flac.New() error = %v, want nil", err)
+	}
+
+	channels := make([][]int16, s.Info.NChannels)
+	h := md5.New()
+	for {
+		f, err := s.ParseNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ParseNext() error = %v, want nil", err)
+		}
+		for i := 0; i < int(f.BlockSize); i++ {
+			for c, sf := range f.Subframes {
+				v := int16(sf.Samples[i])
+				channels[c] = append(channels[c], v)
+				binary.Write(h, binary.LittleEndian, v)
+			}
+		}
+	}
+
+	if got, want := uint64(len(channels[0])), s.Info.NSamples; got != want {
+		t.Errorf("decoded %d sample(s), stream info has %d", got, want)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, s.Info.MD5sum[:]) {
+		t.Errorf("decoded samples have MD5 %x, stream info has %x", got, s.Info.MD5sum)
+	}
+
+	return int(s.Info.SampleRate), channels
+}
+
+func TestWriteFLAC(t *testing.T) {
+	rand := rand.New(rand.NewPCG(1, 2))
+
+	noise := func(n int) []int16 {
+		samples := make([]int16, n)
+		for i := range samples {
+			samples[i] = int16(rand.Uint32())
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name     string
+		channels [][]int16
+	}{
+		{
+			name:     "mono single sample",
+			channels: [][]int16{{1234}},
+		},
+		{
+			name:     "mono silence",
+			channels: [][]int16{make([]int16, 5000)},
+		},
+		{
+			name:     "mono tone",
+			channels: [][]int16{audiotest.Tone(22050, 10000, 440, 16000)},
+		},
+		{
+			name:     "mono noise",
+			channels: [][]int16{noise(4097)},
+		},
+		{
+			name:     "mono extremes",
+			channels: [][]int16{{math.MinInt16, math.MaxInt16, math.MinInt16, math.MaxInt16, 0}},
+		},
+		{
+			name:     "stereo tones",
+			channels: [][]int16{audiotest.Tone(22050, 9000, 440, 12000), audiotest.Tone(22050, 9000, 660, 8000)},
+		},
+		{
+			name:     "stereo identical",
+			channels: [][]int16{audiotest.Tone(22050, 9000, 440, 12000), audiotest.Tone(22050, 9000, 440, 12000)},
+		},
+		{
+			name:     "stereo noise",
+			channels: [][]int16{noise(4100), noise(4100)},
+		},
+		{
+			name: "stereo extremes",
+			channels: [][]int16{
+				{math.MaxInt16, math.MinInt16, math.MaxInt16, math.MinInt16},
+				{math.MinInt16, math.MaxInt16, math.MinInt16, math.MaxInt16},
+			},
+		},
+		{
+			name:     "stereo empty",
+			channels: [][]int16{{}, {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteFLAC(&buf, 22050, tt.channels); err != nil {
+				t.Fatalf("WriteFLAC() error = %v, want nil", err)
+			}
+
+			sampleRate, got := readFLAC(t, buf.Bytes())
+			if sampleRate != 22050 {
+				t.Errorf("sample rate = %d, want %d", sampleRate, 22050)
+			}
+			want := tt.channels
+			if len(want[0]) == 0 {
+				want = make([][]int16, len(want))
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("decoded samples mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteFLAC_compresses(t *testing.T) {
+	samples := audiotest.Tone(22050, 22050, 440, 16000)
+
+	var buf bytes.Buffer
+	if err := WriteFLAC(&buf, 22050, [][]int16{samples, samples}); err != nil {
+		t.Fatalf("WriteFLAC() error = %v, want nil", err)
+	}
+	if raw := len(samples) * 2 * 2; buf.Len() > raw/2 {
+		t.Errorf("WriteFLAC() wrote %d byte(s), want at most half of %d", buf.Len(), raw)
+	}
+}
+
+func TestWriteFLAC_invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels [][]int16
+	}{
+		{name: "no channels", channels: nil},
+		{name: "three channels", channels: [][]int16{{0}, {0}, {0}}},
+		{name: "mismatched lengths", channels: [][]int16{{0, 1}, {0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := WriteFLAC(io.Discard, 22050, tt.channels); err == nil {
+				t.Error("WriteFLAC() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package audio
+
+import "io"
+
+// frameReader is returned by NewFrameReader.
+type frameReader struct {
+	r    io.Reader
+	size int
+	err  error
+
+	// buf[start:end] holds the bytes that have been read from r but not
+	// returned, and pos is the number of bytes that have been returned.
+	buf        [4096]byte
+	start, end int
+	pos        int64
+}
+
+// NewFrameReader returns a reader that reads from r in whole frames of size
+// bytes, such as 2 for a 16-bit mono sample. Bytes of a frame are only
+// returned once the whole frame has been read, so a partial frame at the end
+// of r is dropped.
+func NewFrameReader(r io.Reader, size int) io.Reader {
+	return &frameReader{r: r, size: size}
+}
+
+func (r *frameReader) Read(p []byte) (n int, err error) {
+	for {
+		// The buffered bytes that complete a frame, counting the bytes of the
+		// current frame that have already been returned.
+		returned := int(r.pos % int64(r.size))
+		if complete := (returned+r.end-r.start)/r.size*r.size - returned; complete > 0 {
+			n = copy(p, r.buf[r.start:r.start+complete])
+			r.start += n
+			r.pos += int64(n)
+			return n, nil
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.end = copy(r.buf[:], r.buf[r.start:r.end])
+		r.start = 0
+		n, r.err = r.r.Read(r.buf[r.end:])
+		r.end += n
+	}
+}
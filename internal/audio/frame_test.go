@@ -0,0 +1,43 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewFrameReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		size int
+		want []byte
+	}{
+		{name: "whole frames", in: []byte{1, 2, 3, 4}, size: 2, want: []byte{1, 2, 3, 4}},
+		{name: "partial frame", in: []byte{1, 2, 3, 4, 5, 6, 7}, size: 4, want: []byte{1, 2, 3, 4}},
+		{name: "only a partial frame", in: []byte{1, 2, 3}, size: 4, want: nil},
+		{name: "empty", in: nil, size: 2, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(NewFrameReader(iotest.HalfReader(bytes.NewReader(tt.in)), tt.size))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v, want nil", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("ReadAll() = %v, want %v", got, tt.want)
+			}
+			if err := iotest.TestReader(NewFrameReader(bytes.NewReader(tt.in), tt.size), tt.want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestNewFrameReader_error(t *testing.T) {
+	r := NewFrameReader(iotest.TimeoutReader(bytes.NewReader([]byte{1, 2, 3})), 2)
+	if _, err := io.ReadAll(r); err != iotest.ErrTimeout {
+		t.Errorf("ReadAll() error = %v, want %v", err, iotest.ErrTimeout)
+	}
+}
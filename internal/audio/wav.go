@@ -0,0 +1,259 @@
+// Package audio contains helpers for converting audio to the format of the
+// game's audio files.
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+
+	"github.com/go-audio/wav"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatIEEEFloat  = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// resampleTaps is the number of input samples either side of each output sample
+// that are used when resampling, at the input's sample rate.
+const resampleTaps = 16
+
+// ReadWAV reads the WAV file from r and returns its samples as 16-bit PCM at
+// sampleRate Hz with the given number of channels. The returned slice has one
+// slice of samples per channel.
+//
+// 8, 16, 24 and 32-bit integer and 32-bit float WAV files are supported. Input
+// with a different sample rate is resampled. Input with more channels is
+// mixed down to mono by averaging every channel, or to stereo by keeping the
+// first two channels, which are front left and front right in WAV files. Mono
+// input is copied to every channel. Input with more than 16 bits is dithered
+// to 16 bits.
+func ReadWAV(r io.ReadSeeker, sampleRate, channels int) ([][]int16, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	d := wav.NewDecoder(r)
+	if !d.IsValidFile() {
+		if err := d.Err(); err != nil {
+			return nil, fmt.Errorf("invalid WAV file: %w", err)
+		}
+		return nil, errors.New("invalid WAV file")
+	}
+
+	format := d.WavAudioFormat
+	if format == wavFormatExtensible {
+		// The decoder does not keep the format's extension, so read it again.
+		if format, err = readWAVSubFormat(r, start); err != nil {
+			return nil, fmt.Errorf("could not read WAV sub format: %w", err)
+		}
+		if _, err := r.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		d = wav.NewDecoder(r)
+		if d.ReadInfo(); d.Err() != nil {
+			return nil, fmt.Errorf("invalid WAV file: %w", d.Err())
+		}
+	}
+
+	var float bool
+	switch format {
+	case wavFormatPCM:
+	case wavFormatIEEEFloat:
+		if d.BitDepth != 32 {
+			return nil, fmt.Errorf("unsupported float WAV bit depth %d", d.BitDepth)
+		}
+		float = true
+	default:
+		return nil, fmt.Errorf("unsupported WAV audio format %d", format)
+	}
+
+	buf, err := d.FullPCMBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("could not read PCM data: %w", err)
+	}
+
+	srcChannels := int(d.NumChans)
+	frames := len(buf.Data) / srcChannels
+	depth := int(d.BitDepth)
+
+	// Convert every sample to a float in the range of a 16-bit sample.
+	src := make([][]float64, srcChannels)
+	for c := range src {
+		src[c] = make([]float64, frames)
+		for i := range src[c] {
+			v := buf.Data[i*srcChannels+c]
+			switch {
+			case float:
+				src[c][i] = float64(math.Float32frombits(uint32(v))) * (math.MaxInt16 + 1)
+			case depth == 8:
+				// 8-bit samples are unsigned.
+				src[c][i] = float64(v-0x80) * (1 << 8)
+			default:
+				src[c][i] = math.Ldexp(float64(v), 16-depth)
+			}
+		}
+	}
+
+	mixed := mixChannels(src, channels)
+
+	if rate := int(d.SampleRate); rate != sampleRate {
+		for c := range mixed {
+			mixed[c] = resample(mixed[c], rate, sampleRate)
+		}
+	}
+
+	q := &quantizer{dither: float || depth > 16}
+	if q.dither {
+		q.rand = rand.New(rand.NewPCG(1, 2))
+	}
+
+	out := make([][]int16, channels)
+	for c := range out {
+		out[c] = make([]int16, len(mixed[c]))
+		for i, v := range mixed[c] {
+			out[c][i] = q.quantize(v)
+		}
+	}
+
+	return out, nil
+}
+
+// wavSubFormatGUID is the GUID of a WAVE_FORMAT_EXTENSIBLE sub format without
+// its first 2 bytes, which are the audio format that the sub format stands
+// for, e.g. wavFormatPCM for KSDATAFORMAT_SUBTYPE_PCM.
+const wavSubFormatGUID = "\x00\x00\x00\x00\x10\x00\x80\x00\x00\xAA\x00\x38\x9B\x71"
+
+// readWAVSubFormat reads the WAVE_FORMAT_EXTENSIBLE fmt chunk of the WAV file
+// that starts at offset start in r and returns the audio format of its sub
+// format.
+func readWAVSubFormat(r io.ReadSeeker, start int64) (uint16, error) {
+	if _, err := r.Seek(start+12, io.SeekStart); err != nil {
+		return 0, err
+	}
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return 0, fmt.Errorf("could not find fmt chunk: %w", err)
+		}
+		size := int64(binary.LittleEndian.Uint32(header[4:]))
+		if string(header[:4]) != "fmt " {
+			// Chunks are padded to an even size.
+			if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		// The sub format GUID follows the 16 bytes of the basic format, the
+		// size of the extension and 6 bytes of channel information.
+		var chunk [40]byte
+		if size < int64(len(chunk)) {
+			return 0, fmt.Errorf("fmt chunk is %d byte(s), expected at least %d", size, len(chunk))
+		}
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, fmt.Errorf("could not read fmt chunk: %w", err)
+		}
+		guid := chunk[24:40]
+		if string(guid[2:]) != wavSubFormatGUID {
+			return 0, fmt.Errorf("unsupported sub format %x", guid)
+		}
+		return binary.LittleEndian.Uint16(guid[:2]), nil
+	}
+}
+
+// mixChannels returns src mixed up or down to the given number of channels.
+func mixChannels(src [][]float64, channels int) [][]float64 {
+	switch {
+	case len(src) == channels:
+		return src
+	case len(src) == 1:
+		out := make([][]float64, channels)
+		for c := range out {
+			out[c] = src[0]
+		}
+		return out
+	case channels == 1:
+		mono := make([]float64, len(src[0]))
+		for i := range mono {
+			for c := range src {
+				mono[i] += src[c][i]
+			}
+			mono[i] /= float64(len(src))
+		}
+		return [][]float64{mono}
+	default:
+		return src[:channels]
+	}
+}
+
+// resample returns src, sampled at from Hz, resampled to to Hz using a
+// windowed sinc filter. When downsampling, the filter's cutoff is lowered to
+// the new Nyquist frequency to avoid aliasing.
+func resample(src []float64, from, to int) []float64 {
+	ratio := float64(from) / float64(to)
+	cutoff := min(1, 1/ratio)
+	halfWidth := resampleTaps / cutoff
+
+	dst := make([]float64, int(math.Round(float64(len(src))/ratio)))
+	for n := range dst {
+		t := float64(n) * ratio
+
+		first := max(0, int(math.Ceil(t-halfWidth)))
+		last := min(len(src)-1, int(math.Floor(t+halfWidth)))
+
+		var sum, weights float64
+		for k := first; k <= last; k++ {
+			x := t - float64(k)
+			w := cutoff * sinc(cutoff*x) * blackman(x/halfWidth)
+			sum += src[k] * w
+			weights += w
+		}
+		if weights != 0 {
+			// Normalize so that the edges of the signal, where the filter is
+			// truncated, keep their level.
+			dst[n] = sum / weights
+		}
+	}
+	return dst
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// blackman returns the Blackman window at x in the range [-1, 1].
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
+
+// quantizer rounds samples to 16 bits, optionally adding triangular
+// probability density function (TPDF) dither of up to 1 LSB so that the
+// quantization error is not correlated with the signal.
+type quantizer struct {
+	dither bool
+	rand   *rand.Rand
+}
+
+func (q *quantizer) quantize(v float64) int16 {
+	if q.dither {
+		v += q.rand.Float64() - q.rand.Float64()
+	}
+	return clamp16(math.Round(v))
+}
@@ -0,0 +1,233 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"testing"
+
+	goaudio "github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeWAV returns a WAV file holding the interleaved samples in data.
+func writeWAV(t *testing.T, sampleRate, bitDepth, channels, format int, data []int) io.ReadSeeker {
+	t.Helper()
+
+	tmp, err := os.CreateTemp("", "wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	enc := wav.NewEncoder(tmp, sampleRate, bitDepth, channels, format)
+	if err := enc.Write(&goaudio.IntBuffer{
+		Format:         &goaudio.Format{NumChannels: channels, SampleRate: sampleRate},
+		Data:           data,
+		SourceBitDepth: bitDepth,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(bs)
+}
+
+func TestReadWAV(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate int
+		bitDepth   int
+		channels   int
+		format     int
+		data       []int
+		toChannels int
+		want       [][]int16
+		// tolerance is the largest difference from want that is allowed, which
+		// allows for dither.
+		tolerance int
+	}{
+		{
+			name:       "16-bit mono",
+			sampleRate: 22050,
+			bitDepth:   16,
+			channels:   1,
+			format:     wavFormatPCM,
+			data:       []int{0, 1, -1, math.MaxInt16, math.MinInt16},
+			toChannels: 1,
+			want:       [][]int16{{0, 1, -1, math.MaxInt16, math.MinInt16}},
+		},
+		{
+			name:       "8-bit mono",
+			sampleRate: 22050,
+			bitDepth:   8,
+			channels:   1,
+			format:     wavFormatPCM,
+			data:       []int{0x80, 0x81, 0x7F, 0xFF, 0x00},
+			toChannels: 1,
+			want:       [][]int16{{0, 0x100, -0x100, 0x7F00, math.MinInt16}},
+		},
+		{
+			name:       "16-bit mono to stereo",
+			sampleRate: 22050,
+			bitDepth:   16,
+			channels:   1,
+			format:     wavFormatPCM,
+			data:       []int{100, -200},
+			toChannels: 2,
+			want:       [][]int16{{100, -200}, {100, -200}},
+		},
+		{
+			name:       "24-bit stereo to mono",
+			sampleRate: 22050,
+			bitDepth:   24,
+			channels:   2,
+			format:     wavFormatPCM,
+			data:       []int{100 << 8, 300 << 8, -1000 << 8, 0, 0x7FFFFF, 0x7FFFFF},
+			toChannels: 1,
+			want:       [][]int16{{200, -500, math.MaxInt16}},
+			tolerance:  1,
+		},
+		{
+			name:       "32-bit stereo",
+			sampleRate: 22050,
+			bitDepth:   32,
+			channels:   2,
+			format:     wavFormatPCM,
+			data:       []int{100 << 16, -100 << 16},
+			toChannels: 2,
+			want:       [][]int16{{100}, {-100}},
+			tolerance:  1,
+		},
+		{
+			name:       "float mono",
+			sampleRate: 22050,
+			bitDepth:   32,
+			channels:   1,
+			format:     wavFormatIEEEFloat,
+			data:       floatBits(0, 0.5, -0.25, 1),
+			toChannels: 1,
+			want:       [][]int16{{0, 16384, -8192, math.MaxInt16}},
+			tolerance:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := writeWAV(t, tt.sampleRate, tt.bitDepth, tt.channels, tt.format, tt.data)
+
+			got, err := ReadWAV(r, 22050, tt.toChannels)
+			if err != nil {
+				t.Fatalf("ReadWAV() error = %v, want nil", err)
+			}
+
+			approx := cmp.Comparer(func(x, y int16) bool {
+				d := int(x) - int(y)
+				return -tt.tolerance <= d && d <= tt.tolerance
+			})
+			if diff := cmp.Diff(tt.want, got, approx); diff != "" {
+				t.Errorf("ReadWAV() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadWAV_resample(t *testing.T) {
+	// One second of a 440 Hz tone at 44100 Hz.
+	data := make([]float64, 44100)
+	for i := range data {
+		data[i] = 0.5 * math.Sin(2*math.Pi*440*float64(i)/44100)
+	}
+	r := writeWAV(t, 44100, 32, 1, wavFormatIEEEFloat, floatBits(data...))
+
+	got, err := ReadWAV(r, 22050, 1)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v, want nil", err)
+	}
+	if n := len(got[0]); n != 22050 {
+		t.Fatalf("ReadWAV() returned %d sample(s), want %d", n, 22050)
+	}
+
+	// Away from the edges, the resampled tone should match the same tone
+	// sampled at 22050 Hz.
+	for i := resampleTaps; i < len(got[0])-resampleTaps; i++ {
+		want := 16384 * math.Sin(2*math.Pi*440*float64(i)/22050)
+		if d := math.Abs(float64(got[0][i]) - want); d > 8 {
+			t.Fatalf("sample %d = %d, want %.0f", i, got[0][i], want)
+		}
+	}
+}
+
+// writeExtensibleWAV returns a 16-bit mono WAVE_FORMAT_EXTENSIBLE file holding
+// the samples in data, whose sub format is the given audio format.
+func writeExtensibleWAV(subFormat uint16, data []byte) io.ReadSeeker {
+	le := binary.LittleEndian
+
+	var fmtChunk []byte
+	fmtChunk = le.AppendUint16(fmtChunk, wavFormatExtensible)
+	fmtChunk = le.AppendUint16(fmtChunk, 1)
+	fmtChunk = le.AppendUint32(fmtChunk, 22050)
+	fmtChunk = le.AppendUint32(fmtChunk, 22050*2)
+	fmtChunk = le.AppendUint16(fmtChunk, 2)
+	fmtChunk = le.AppendUint16(fmtChunk, 16)
+	fmtChunk = le.AppendUint16(fmtChunk, 22)
+	fmtChunk = le.AppendUint16(fmtChunk, 16)
+	fmtChunk = le.AppendUint32(fmtChunk, 0x4)
+	fmtChunk = le.AppendUint16(fmtChunk, subFormat)
+	fmtChunk = append(fmtChunk, wavSubFormatGUID...)
+
+	var bs []byte
+	bs = append(bs, "RIFF"...)
+	bs = le.AppendUint32(bs, uint32(4+8+len(fmtChunk)+8+len(data)))
+	bs = append(bs, "WAVE"...)
+	bs = append(bs, "fmt "...)
+	bs = le.AppendUint32(bs, uint32(len(fmtChunk)))
+	bs = append(bs, fmtChunk...)
+	bs = append(bs, "data"...)
+	bs = le.AppendUint32(bs, uint32(len(data)))
+	bs = append(bs, data...)
+	return bytes.NewReader(bs)
+}
+
+func TestReadWAV_extensible(t *testing.T) {
+	data := []byte{0x00, 0x00, 0x64, 0x00, 0x9C, 0xFF}
+
+	got, err := ReadWAV(writeExtensibleWAV(wavFormatPCM, data), 22050, 1)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v, want nil", err)
+	}
+	if diff := cmp.Diff([][]int16{{0, 100, -100}}, got); diff != "" {
+		t.Errorf("ReadWAV() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A sub format other than PCM or float, such as A-law, is rejected.
+	const wavFormatALaw = 6
+	if _, err := ReadWAV(writeExtensibleWAV(wavFormatALaw, data), 22050, 1); err == nil {
+		t.Error("ReadWAV() with A-law sub format error = nil, want error")
+	}
+}
+
+func TestReadWAV_invalid(t *testing.T) {
+	if _, err := ReadWAV(bytes.NewReader([]byte("not a WAV file")), 22050, 1); err == nil {
+		t.Error("ReadWAV() error = nil, want error")
+	}
+}
+
+// floatBits returns the bits of each value as a 32-bit float, which is how the
+// WAV encoder is given float samples.
+func floatBits(vs ...float64) []int {
+	data := make([]int, len(vs))
+	for i, v := range vs {
+		data[i] = int(int32(math.Float32bits(float32(v))))
+	}
+	return data
+}
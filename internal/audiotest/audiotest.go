@@ -0,0 +1,33 @@
+// Package audiotest provides test signals and measurements for the audio
+// packages.
+package audiotest
+
+import "math"
+
+// Warmup is the number of samples at the start of an IMA ADPCM encoded tone
+// that are left out of comparisons while the encoder's step index adapts to
+// the tone.
+const Warmup = 32
+
+// Tone returns n samples of a sine wave of freq Hz, sampled at sampleRate Hz,
+// with a peak of amp.
+func Tone(sampleRate, n int, freq, amp float64) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(math.Round(amp * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))))
+	}
+	return samples
+}
+
+// SignalToNoise returns the ratio, in decibels, of the power of want to the
+// power of the difference between got and want.
+func SignalToNoise(want, got []int16) float64 {
+	var signal, noise float64
+	for i := range want {
+		s := float64(want[i])
+		n := float64(got[i]) - s
+		signal += s * s
+		noise += n * n
+	}
+	return 10 * math.Log10(signal/noise)
+}
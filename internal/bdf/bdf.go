@@ -0,0 +1,209 @@
+// Package bdf implements parsing of Glyph Bitmap Distribution Format (BDF)
+// fonts.
+//
+// Only the parts of the format needed to rasterize glyphs are supported. The
+// specification is at
+// https://adobe-type-tools.github.io/font-tech-notes/pdfs/5005.BDF_Spec.pdf.
+package bdf
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Face is a parsed BDF font. It implements golang.org/x/image/font.Face.
+type Face struct {
+	ascent  int
+	descent int
+	glyphs  map[rune]*glyph
+}
+
+var _ font.Face = (*Face)(nil)
+
+type glyph struct {
+	advance int
+	// bounds is the glyph's bounding box relative to the origin, with y
+	// increasing downwards.
+	bounds image.Rectangle
+	mask   *image.Alpha
+}
+
+// Parse parses a BDF font from r. Glyphs are keyed by their ENCODING, which is
+// assumed to be a Unicode code point. Unencoded glyphs are ignored.
+func Parse(r io.Reader) (*Face, error) {
+	f := &Face{glyphs: make(map[rune]*glyph)}
+
+	s := bufio.NewScanner(r)
+	line := 0
+
+	var (
+		g        *glyph
+		encoding = -1
+		bitmap   = -1
+	)
+
+	for s.Scan() {
+		line++
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if bitmap >= 0 && fields[0] != "ENDCHAR" {
+			if bitmap >= g.bounds.Dy() {
+				return nil, fmt.Errorf("line %d: too many bitmap rows, expected %d", line, g.bounds.Dy())
+			}
+			row, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid bitmap row: %w", line, err)
+			}
+			for x := 0; x < g.bounds.Dx(); x++ {
+				if x/8 < len(row) && row[x/8]&(0x80>>(x%8)) != 0 {
+					g.mask.SetAlpha(x, bitmap, color.Alpha{A: 0xff})
+				}
+			}
+			bitmap++
+			continue
+		}
+
+		ints := func(n int) ([]int, error) {
+			if len(fields)-1 < n {
+				return nil, fmt.Errorf("line %d: %s has %d value(s), expected %d", line, fields[0], len(fields)-1, n)
+			}
+			vs := make([]int, n)
+			for i := range vs {
+				v, err := strconv.Atoi(fields[i+1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s value: %w", line, fields[0], err)
+				}
+				vs[i] = v
+			}
+			return vs, nil
+		}
+
+		switch fields[0] {
+		case "FONT_ASCENT":
+			vs, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			f.ascent = vs[0]
+		case "FONT_DESCENT":
+			vs, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			f.descent = vs[0]
+		case "STARTCHAR":
+			g = &glyph{}
+			encoding = -1
+		case "ENCODING":
+			vs, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			encoding = vs[0]
+		case "DWIDTH":
+			if g == nil {
+				continue
+			}
+			vs, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			g.advance = vs[0]
+		case "BBX":
+			if g == nil {
+				continue
+			}
+			vs, err := ints(4)
+			if err != nil {
+				return nil, err
+			}
+			w, h, xoff, yoff := vs[0], vs[1], vs[2], vs[3]
+			g.bounds = image.Rect(xoff, -(yoff + h), xoff+w, -yoff)
+		case "BITMAP":
+			if g == nil {
+				return nil, fmt.Errorf("line %d: BITMAP outside of a glyph", line)
+			}
+			g.mask = image.NewAlpha(image.Rect(0, 0, g.bounds.Dx(), g.bounds.Dy()))
+			bitmap = 0
+		case "ENDCHAR":
+			if g == nil {
+				return nil, fmt.Errorf("line %d: ENDCHAR outside of a glyph", line)
+			}
+			if g.mask == nil {
+				g.mask = image.NewAlpha(image.Rectangle{})
+			}
+			if encoding >= 0 {
+				f.glyphs[rune(encoding)] = g
+			}
+			g = nil
+			bitmap = -1
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if g != nil {
+		return nil, fmt.Errorf("line %d: unexpected end of font inside a glyph", line)
+	}
+
+	return f, nil
+}
+
+// Close implements font.Face. It does nothing and always returns nil.
+func (f *Face) Close() error { return nil }
+
+// Glyph implements font.Face.
+func (f *Face) Glyph(dot fixed.Point26_6, r rune) (
+	dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+
+	g, ok := f.glyphs[r]
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	dr = g.bounds.Add(image.Pt(dot.X.Round(), dot.Y.Round()))
+	return dr, g.mask, image.Point{}, fixed.I(g.advance), true
+}
+
+// GlyphBounds implements font.Face.
+func (f *Face) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	g, ok := f.glyphs[r]
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	b := g.bounds
+	return fixed.R(b.Min.X, b.Min.Y, b.Max.X, b.Max.Y), fixed.I(g.advance), true
+}
+
+// GlyphAdvance implements font.Face.
+func (f *Face) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	g, ok := f.glyphs[r]
+	if !ok {
+		return 0, false
+	}
+	return fixed.I(g.advance), true
+}
+
+// Kern implements font.Face. BDF fonts have no kerning so it always returns 0.
+func (f *Face) Kern(r0, r1 rune) fixed.Int26_6 { return 0 }
+
+// Metrics implements font.Face.
+func (f *Face) Metrics() font.Metrics {
+	return font.Metrics{
+		Height:     fixed.I(f.ascent + f.descent),
+		Ascent:     fixed.I(f.ascent),
+		Descent:    fixed.I(f.descent),
+		CaretSlope: image.Point{X: 0, Y: 1},
+	}
+}
@@ -0,0 +1,94 @@
+package bdf
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestParse(t *testing.T) {
+	const font = `STARTFONT 2.1
+STARTPROPERTIES 2
+FONT_ASCENT 6
+FONT_DESCENT 2
+ENDPROPERTIES
+CHARS 1
+STARTCHAR g
+ENCODING 103
+DWIDTH 5 0
+BBX 3 4 1 -2
+BITMAP
+E0
+A0
+E0
+20
+ENDCHAR
+ENDFONT
+`
+	f, err := Parse(strings.NewReader(font))
+	if err != nil {
+		t.Fatalf("Parse() error = %v, want nil", err)
+	}
+
+	bounds, advance, ok := f.GlyphBounds('g')
+	if want := fixed.R(1, -2, 4, 2); !ok || bounds != want || advance != fixed.I(5) {
+		t.Errorf("GlyphBounds() = %v, %v, %v, want %v, %v, true", bounds, advance, ok, want, fixed.I(5))
+	}
+
+	dr, mask, _, _, ok := f.Glyph(fixed.P(10, 20), 'g')
+	if want := image.Rect(11, 18, 14, 22); !ok || dr != want {
+		t.Fatalf("Glyph() dr = %v, %v, want %v, true", dr, ok, want)
+	}
+	var got string
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 3; x++ {
+			if _, _, _, a := mask.At(x, y).RGBA(); a != 0 {
+				got += "#"
+			} else {
+				got += "."
+			}
+		}
+		got += "\n"
+	}
+	if want := "###\n#.#\n###\n..#\n"; got != want {
+		t.Errorf("Glyph() mask =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, ok := f.GlyphAdvance('h'); ok {
+		t.Errorf("GlyphAdvance() of missing glyph ok = true, want false")
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		font    string
+		wantErr string
+	}{
+		{
+			name:    "too many bitmap rows",
+			font:    "STARTCHAR a\nENCODING 97\nBBX 8 1 0 0\nBITMAP\nFF\nFF\nENDCHAR\n",
+			wantErr: "line 6: too many bitmap rows, expected 1",
+		},
+		{
+			name:    "invalid BBX",
+			font:    "STARTCHAR a\nBBX 8 x 0 0\n",
+			wantErr: `line 2: invalid BBX value: strconv.Atoi: parsing "x": invalid syntax`,
+		},
+		{
+			name:    "unterminated glyph",
+			font:    "STARTCHAR a\nENCODING 97\n",
+			wantErr: "line 2: unexpected end of font inside a glyph",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.font))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Package readerutil contains utility functions for working with readers.
+package readerutil
+
+import "io"
+
+// Skip discards the next n bytes of r. If r is an io.Seeker, it seeks past
+// them instead of reading them. It returns io.ErrUnexpectedEOF if r has fewer
+// than n bytes remaining.
+func Skip(r io.Reader, n int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		pos, err := s.Seek(n, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		end, err := s.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if pos > end {
+			return io.ErrUnexpectedEOF
+		}
+		_, err = s.Seek(pos, io.SeekStart)
+		return err
+	}
+
+	m, err := io.CopyN(io.Discard, r, n)
+	if m < n && err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Remaining discards the rest of r and returns the number of bytes that were
+// discarded. If r is an io.Seeker, it seeks to the end instead of reading.
+func Remaining(r io.Reader) (int64, error) {
+	if s, ok := r.(io.Seeker); ok {
+		pos, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		end, err := s.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		return end - pos, nil
+	}
+
+	return io.Copy(io.Discard, r)
+}
+
+// ReadFullAt reads exactly len(p) bytes from r at off. It returns
+// io.ErrUnexpectedEOF if r has fewer than len(p) bytes at off.
+func ReadFullAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package readerutil
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name string
+		r    func(s string) io.Reader
+	}{
+		{
+			name: "seeker",
+			r:    func(s string) io.Reader { return strings.NewReader(s) },
+		},
+		{
+			name: "reader",
+			r:    func(s string) io.Reader { return bytes.NewBufferString(s) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.r("Morgan Bernhardt")
+			if err := Skip(r, 7); err != nil {
+				t.Fatalf("Skip() error = %v, want nil", err)
+			}
+			n, err := Remaining(r)
+			if err != nil {
+				t.Fatalf("Remaining() error = %v, want nil", err)
+			}
+			if n != 9 {
+				t.Errorf("Remaining() = %d, want %d", n, 9)
+			}
+
+			if err := Skip(tt.r("Morgan"), 7); err != io.ErrUnexpectedEOF {
+				t.Errorf("Skip() error = %v, want %v", err, io.ErrUnexpectedEOF)
+			}
+		})
+	}
+}
+
+func TestReadFullAt(t *testing.T) {
+	r := strings.NewReader("Morgan Bernhardt")
+
+	p := make([]byte, 9)
+	if err := ReadFullAt(r, p, 7); err != nil {
+		t.Fatalf("ReadFullAt() error = %v, want nil", err)
+	}
+	if got, want := string(p), "Bernhardt"; got != want {
+		t.Errorf("ReadFullAt() read %q, want %q", got, want)
+	}
+
+	if err := ReadFullAt(r, p, 8); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadFullAt() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}